@@ -0,0 +1,114 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/sliceutil"
+)
+
+var (
+	ErrChecksumMismatch    = errors.New("applied migration content changed")
+	ErrMigrationNotApplied = errors.New("migration is not applied")
+)
+
+// ChecksumMismatchError is returned when the content of applied migrations
+// no longer matches the checksum recorded when they were applied.
+type ChecksumMismatchError struct {
+	// Migrations lists the keys of the modified migrations.
+	Migrations []string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s:\n  - %s",
+		ErrChecksumMismatch,
+		strings.Join(e.Migrations, "\n  - "),
+	)
+}
+
+func (e *ChecksumMismatchError) Unwrap() error { return ErrChecksumMismatch }
+
+// verifyChecksums returns a [*ChecksumMismatchError] listing the modified
+// migrations in report, if any.
+func verifyChecksums(report *StatusReport) error {
+	modified := sliceutil.Filter(report.Migrations, func(s *MigrationStatus) bool {
+		return s.Modified()
+	})
+	if len(modified) == 0 {
+		return nil
+	}
+
+	return &ChecksumMismatchError{
+		Migrations: sliceutil.Map(modified, func(s *MigrationStatus) string { return s.Key() }),
+	}
+}
+
+// Repair records the current content checksum of applied migrations whose
+// content changed since they were applied, or that were applied before
+// checksums were recorded. It returns the keys of the repaired migrations.
+//
+// When keys is empty, every such migration is repaired. Otherwise only the
+// listed migrations are, and each of them must be applied, or
+// [ErrMigrationNotApplied] is returned.
+//
+// Repair does not run any migration SQL: it is how an operator accepts a
+// deliberate edit to an applied migration.
+func (m *Migrator) Repair(ctx context.Context, db DB, keys ...string) ([]string, error) {
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.lockHistory(ctx, s); err != nil {
+		s.close()
+
+		return nil, err
+	}
+	defer m.unlock(ctx, s)
+
+	report, err := m.Status(ctx, s.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := report.Applied()
+
+	for _, key := range keys {
+		if !slices.ContainsFunc(applied, func(status *MigrationStatus) bool { return status.Key() == key }) {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationNotApplied, key)
+		}
+	}
+
+	history := dbsqlc.WithTable(s.conn, m.table)
+
+	var repaired []string
+
+	for _, status := range applied {
+		if len(keys) > 0 && !slices.Contains(keys, status.Key()) {
+			continue
+		}
+
+		checksum := status.Migration.Checksum(DirectionUp)
+		if checksum == status.Checksum {
+			continue
+		}
+
+		if err := dbsqlc.New().UpdateMigrationChecksum(ctx, history, dbsqlc.UpdateMigrationChecksumParams{
+			Checksum:  checksum,
+			Namespace: status.Namespace,
+			Version:   status.Version.String(),
+			Name:      status.Name,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to repair migration %s: %w", status.Key(), err)
+		}
+
+		repaired = append(repaired, status.Key())
+	}
+
+	return repaired, nil
+}
@@ -0,0 +1,96 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Checksum(t *testing.T) {
+	t.Parallel()
+
+	original := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+	}
+	edited := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id BIGINT);",
+		"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+	}
+
+	t.Run("should return ChecksumMismatchError, when applied migration was edited", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+
+		seq, err := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, original)),
+			conduit.WithSkipSchemaDriftCheck(),
+		).Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, edited)),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		// Act
+		_, err = m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		var mismatchErr *conduit.ChecksumMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, []string{"20230601120000_create_a"}, mismatchErr.Migrations)
+	})
+
+	t.Run("should migrate, when edited migration was repaired", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+
+		seq, err := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, original)),
+			conduit.WithSkipSchemaDriftCheck(),
+		).Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, edited)),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		// Act
+		repaired, err := m.Repair(t.Context(), conn)
+		require.NoError(t, err)
+
+		seq, err = m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_a"}, repaired)
+		assert.Len(t, testutil.CollectSeq2(t, seq), 1)
+		assert.Len(t, appliedMigrations(t, pool), 2)
+	})
+
+	t.Run("should return error, when repairing a migration that is not applied", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, edited)))
+
+		// Act
+		_, err := m.Repair(t.Context(), conn, "20230602120000_create_b")
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrMigrationNotApplied)
+	})
+}
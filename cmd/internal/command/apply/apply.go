@@ -2,10 +2,12 @@ package apply
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/spf13/afero"
@@ -18,13 +20,30 @@ import (
 	"go.inout.gg/conduit/conduitregistry"
 	"go.inout.gg/conduit/internal/cmdutil"
 	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/pkg/conduitversion"
 	"go.inout.gg/conduit/pkg/stopwatch"
 )
 
 const (
 	stepsFlag        = "steps"
+	toFlag           = "to"
 	allowHazardsFlag = "allow-hazards"
 	dryRunFlag       = "dry-run"
+	verifySchemaFlag = "verify-schema"
+	setFlag          = "set"
+	retriesFlag      = "retries"
+	retryBackoffFlag = "retry-backoff"
+
+	tenantConcurrencyFlag = "tenant-concurrency"
+	tenantFailureFlag     = "tenant-failure"
+)
+
+const (
+	// redoArg is the direction argument that redoes the latest migrations.
+	redoArg = "redo"
+
+	// resumeArg is the direction argument that resumes the dirty migration.
+	resumeArg = "resume"
 )
 
 func NewCommand(
@@ -36,12 +55,14 @@ func NewCommand(
 ) *cli.Command {
 	//nolint:exhaustruct
 	return &cli.Command{
-		Name:  "apply",
-		Usage: "apply migrations in the given direction",
+		Name:      "apply",
+		Usage:     "apply migrations in the given direction, redo the latest ones or resume a failed one",
+		ArgsUsage: "up|down|redo|resume",
 		Flags: []cli.Flag{
 			//nolint:exhaustruct
 			cmdutil.DatabaseURLFlag(src),
 			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
 
 			//nolint:exhaustruct
 			&cli.IntFlag{
@@ -53,6 +74,16 @@ func NewCommand(
 				),
 			},
 
+			//nolint:exhaustruct
+			&cli.StringFlag{
+				Name: toFlag,
+				Usage: "migrate to a version (YYYYMMDDHHMMSS) or an RFC 3339 timestamp; " +
+					"the direction argument is optional",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_TO"),
+				),
+			},
+
 			//nolint:exhaustruct
 			&cli.StringSliceFlag{
 				Name:  allowHazardsFlag,
@@ -65,6 +96,84 @@ func NewCommand(
 
 			cmdutil.SkipSchemaDriftCheckFlag(src),
 
+			//nolint:exhaustruct
+			&cli.BoolFlag{
+				Name: verifySchemaFlag,
+				Usage: "after each migration, compare the schema with the migrations replayed " +
+					"in a temporary database",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_VERIFY_SCHEMA"),
+					yamlsrc.YAML("apply.verify-schema", src),
+				),
+			},
+
+			//nolint:exhaustruct
+			&cli.StringSliceFlag{
+				Name: setFlag,
+				Usage: "configuration parameter to run migrations with (e.g. lock_timeout=3s), " +
+					"unless they set it with a directive; may be repeated",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_SET"),
+					yamlsrc.YAML("apply.set", src),
+				),
+			},
+
+			//nolint:exhaustruct
+			&cli.IntFlag{
+				Name: retriesFlag,
+				Usage: "times to retry a migration failing on a lock timeout or a deadlock, " +
+					"unless it declares a retry directive",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_RETRIES"),
+					yamlsrc.YAML("apply.retries", src),
+				),
+			},
+
+			//nolint:exhaustruct
+			&cli.DurationFlag{
+				Name:  retryBackoffFlag,
+				Usage: "delay before the first retry, doubled before each following one up to 1m",
+				Value: conduitregistry.DefaultRetryBackoff,
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_RETRY_BACKOFF"),
+					yamlsrc.YAML("apply.retry-backoff", src),
+				),
+			},
+
+			cmdutil.TagsFlag(src),
+			cmdutil.ExcludeTagsFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+			cmdutil.LockTimeoutFlag(src),
+			cmdutil.OutOfOrderFlag(src),
+			cmdutil.OrphanedFlag(src),
+			cmdutil.TenantsQueryFlag(src),
+
+			//nolint:exhaustruct
+			&cli.IntFlag{
+				Name: tenantConcurrencyFlag,
+				Usage: "number of tenants migrated at once with --" + cmdutil.TenantsQuery +
+					"; a dry run previews them one at a time",
+				Value: conduit.DefaultTenantConcurrency,
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_TENANT_CONCURRENCY"),
+					yamlsrc.YAML("tenants.concurrency", src),
+				),
+			},
+
+			//nolint:exhaustruct
+			&cli.StringFlag{
+				Name: tenantFailureFlag,
+				Usage: "what to do when a tenant fails with --" + cmdutil.TenantsQuery +
+					": stop, or continue with the other tenants",
+				Value: string(conduit.TenantFailureStop),
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_TENANT_FAILURE"),
+					yamlsrc.YAML("tenants.failure", src),
+				),
+			},
+
 			//nolint:exhaustruct
 			&cli.BoolFlag{
 				Name:  dryRunFlag,
@@ -77,21 +186,80 @@ func NewCommand(
 			cmdutil.VerboseFlag(src),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			dir, err := direction.FromString(cmd.Args().First())
+			to, toTime, err := parseTarget(cmd.String(toFlag))
+			if err != nil {
+				return err
+			}
+
+			isRedo := cmd.Args().First() == redoArg
+			isResume := cmd.Args().First() == resumeArg
+
+			if (isRedo || isResume) && (!to.IsZero() || !toTime.IsZero()) {
+				return fmt.Errorf("--%s cannot be used with %s", toFlag, cmd.Args().First())
+			}
+
+			var dir direction.Direction
+			if !isRedo && !isResume && (cmd.Args().Present() || (to.IsZero() && toTime.IsZero())) {
+				dir, err = direction.FromString(cmd.Args().First())
+				if err != nil {
+					return fmt.Errorf("failed to parse direction: %w", err)
+				}
+			}
+
+			outOfOrder, err := conduit.ParseConsistencyPolicy(cmd.String(cmdutil.OutOfOrder))
 			if err != nil {
-				return fmt.Errorf("failed to parse direction: %w", err)
+				return fmt.Errorf("failed to parse --%s: %w", cmdutil.OutOfOrder, err)
+			}
+
+			orphaned, err := conduit.ParseConsistencyPolicy(cmd.String(cmdutil.Orphaned))
+			if err != nil {
+				return fmt.Errorf("failed to parse --%s: %w", cmdutil.Orphaned, err)
+			}
+
+			settings := make([]conduit.Setting, 0, len(cmd.StringSlice(setFlag)))
+			for _, raw := range cmd.StringSlice(setFlag) {
+				setting, err := conduitregistry.ParseSetting(raw)
+				if err != nil {
+					return fmt.Errorf("failed to parse --%s: %w", setFlag, err)
+				}
+
+				settings = append(settings, setting)
+			}
+
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
 			}
 
-			migrationsDir := cmd.String(cmdutil.MigrationsDir)
 			isDryRun := cmd.Bool(dryRunFlag)
 
 			opts := []conduit.Option{
-				conduit.WithRegistry(conduitregistry.FromFS(fs, migrationsDir)),
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+				conduit.WithLockTimeout(cmd.Duration(cmdutil.LockTimeout)),
+				conduit.WithOutOfOrderPolicy(outOfOrder),
+				conduit.WithOrphanedPolicy(orphaned),
+				conduit.WithDefaultSettings(settings...),
+				conduit.WithRetryPolicy(conduit.RetryPolicy{
+					MaxRetries: cmd.Int(retriesFlag),
+					Backoff:    cmd.Duration(retryBackoffFlag),
+				}),
+				conduit.WithTags(conduit.TagFilter{
+					Include: cmd.StringSlice(cmdutil.Tags),
+					Exclude: cmd.StringSlice(cmdutil.ExcludeTags),
+				}),
 			}
 			if cmd.Bool(cmdutil.SkipSchemaDriftCheck) {
 				opts = append(opts, conduit.WithSkipSchemaDriftCheck())
 			}
 
+			if cmd.Bool(verifySchemaFlag) {
+				opts = append(opts, conduit.WithSchemaVerification())
+			}
+
 			if isDryRun {
 				opts = append(opts, conduit.WithExecutor(
 					conduit.NewDryRunExecutor(stdout, cmd.Bool(cmdutil.Verbose)),
@@ -102,13 +270,94 @@ func NewCommand(
 				))
 			}
 
+			if tenantsQuery := cmd.String(cmdutil.TenantsQuery); tenantsQuery != "" {
+				if isRedo || isResume {
+					return fmt.Errorf("--%s cannot be used with %s", cmdutil.TenantsQuery, cmd.Args().First())
+				}
+
+				failurePolicy, err := conduit.ParseTenantFailurePolicy(cmd.String(tenantFailureFlag))
+				if err != nil {
+					return fmt.Errorf("failed to parse --%s: %w", tenantFailureFlag, err)
+				}
+
+				concurrency := cmd.Int(tenantConcurrencyFlag)
+				if isDryRun && concurrency != 1 {
+					// The previews of tenants run at once would interleave.
+					if cmd.IsSet(tenantConcurrencyFlag) {
+						fmt.Fprintf(
+							stderr, "Previewing one tenant at a time: --%s is ignored with --%s.\n",
+							tenantConcurrencyFlag, dryRunFlag,
+						)
+					}
+
+					concurrency = 1
+				}
+
+				runner := conduit.NewTenantRunner(
+					opts,
+					conduit.WithTenantConcurrency(concurrency),
+					conduit.WithTenantFailurePolicy(failurePolicy),
+				)
+
+				seq, err := conduitcli.ApplyTenants(ctx, runner, conduitcli.ApplyTenantsArgs{
+					TenantsQuery: tenantsQuery,
+					ApplyArgs: conduitcli.ApplyArgs{
+						DatabaseURL:  cmd.String(cmdutil.DatabaseURL),
+						Direction:    dir,
+						Steps:        cmd.Int(stepsFlag),
+						AllowHazards: cmd.StringSlice(allowHazardsFlag),
+						To:           to,
+						ToTime:       toTime,
+					},
+				})
+				if err != nil {
+					//nolint:wrapcheck
+					return err
+				}
+
+				return displayTenantResults(stderr, seq, isDryRun)
+			}
+
 			migrator := conduit.NewMigrator(opts...)
 
+			if isRedo {
+				seq, err := conduitcli.Redo(ctx, migrator, conduitcli.RedoArgs{
+					DatabaseURL:  cmd.String(cmdutil.DatabaseURL),
+					Steps:        cmd.Int(stepsFlag),
+					AllowHazards: cmd.StringSlice(allowHazardsFlag),
+				})
+				if err != nil {
+					//nolint:wrapcheck
+					return err
+				}
+
+				return displayResults(stderr, seq, direction.DirectionDown, isDryRun)
+			}
+
+			if isResume {
+				result, err := conduitcli.Resume(ctx, migrator, conduitcli.ResumeArgs{
+					DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+				})
+				if err != nil {
+					//nolint:wrapcheck
+					return err
+				}
+
+				fmt.Fprintf(
+					stderr, "Resumed %s %s (%s)\n",
+					result.Direction, result.Key(), formatResult(result),
+				)
+
+				return nil
+			}
+
 			args := conduitcli.ApplyArgs{
 				DatabaseURL:  cmd.String(cmdutil.DatabaseURL),
 				Direction:    dir,
 				Steps:        cmd.Int(stepsFlag),
 				AllowHazards: cmd.StringSlice(allowHazardsFlag),
+				To:           to,
+				ToTime:       toTime,
 			}
 
 			seq, err := conduitcli.Apply(ctx, migrator, args)
@@ -129,8 +378,8 @@ func displayResults(
 	isDryRun bool,
 ) error {
 	var (
-		n     int
-		total time.Duration
+		applied, rolledBack int
+		total               time.Duration
 	)
 
 	for m, err := range seq {
@@ -138,30 +387,35 @@ func displayResults(
 			return err
 		}
 
-		n++
 		total += m.DurationTotal
+		dir = m.Direction
+
+		if dir == direction.DirectionDown {
+			rolledBack++
+		} else {
+			applied++
+		}
 
 		switch {
 		case isDryRun:
-			fmt.Fprintf(w, "Pending %s_%s\n", m.Version.String(), m.Name)
+			fmt.Fprintf(w, "Pending %s\n", m.Key())
 		case dir == direction.DirectionDown:
-			fmt.Fprintf(
-				w, "Rolled back %s_%s (%s)\n",
-				m.Version.String(), m.Name, formatDuration(m.DurationTotal),
-			)
+			fmt.Fprintf(w, "Rolled back %s (%s)\n", m.Key(), formatResult(m))
 		default:
-			fmt.Fprintf(
-				w, "Applied %s_%s (%s)\n",
-				m.Version.String(), m.Name, formatDuration(m.DurationTotal),
-			)
+			fmt.Fprintf(w, "Applied %s (%s)\n", m.Key(), formatResult(m))
 		}
 	}
 
+	n := applied + rolledBack
+
 	if n == 0 {
-		if dir == direction.DirectionUp {
+		switch dir {
+		case direction.DirectionUp:
 			fmt.Fprintln(w, "No pending migrations.")
-		} else {
+		case direction.DirectionDown:
 			fmt.Fprintln(w, "No migrations to roll back.")
+		default:
+			fmt.Fprintln(w, "Already at the target version.")
 		}
 
 		return nil
@@ -172,6 +426,11 @@ func displayResults(
 	switch {
 	case isDryRun:
 		fmt.Fprintf(w, "%d pending migrations (dry run)\n", n)
+	case applied > 0 && rolledBack > 0:
+		fmt.Fprintf(
+			w, "Rolled back %d and applied %d migrations in %s\n",
+			rolledBack, applied, formatDuration(total),
+		)
 	case dir == direction.DirectionDown:
 		fmt.Fprintf(w, "Rolled back %d migrations in %s\n", n, formatDuration(total))
 	default:
@@ -181,6 +440,105 @@ func displayResults(
 	return nil
 }
 
+// displayTenantResults prints the results of a --tenants-query run as they
+// come in. Under the continue failure policy, failed tenants are reported as
+// they fail, and together at the end.
+func displayTenantResults(
+	w io.Writer,
+	seq iter.Seq2[*conduit.TenantResult, error],
+	isDryRun bool,
+) error {
+	var (
+		n       int
+		total   time.Duration
+		failed  []string
+		tenants = make(map[string]struct{})
+	)
+
+	for r, err := range seq {
+		if err != nil {
+			var tenantErr *conduit.TenantError
+			if !errors.As(err, &tenantErr) {
+				return err
+			}
+
+			failed = append(failed, tenantErr.Schema)
+			fmt.Fprintf(w, "Failed %s: %v\n", tenantErr.Schema, tenantErr.Err)
+
+			continue
+		}
+
+		m := r.Result
+		n++
+		total += m.DurationTotal
+		tenants[r.Schema] = struct{}{}
+
+		switch {
+		case isDryRun:
+			fmt.Fprintf(w, "Pending %s in %s\n", m.Key(), r.Schema)
+		case m.Direction == direction.DirectionDown:
+			fmt.Fprintf(w, "Rolled back %s in %s (%s)\n", m.Key(), r.Schema, formatResult(m))
+		default:
+			fmt.Fprintf(w, "Applied %s in %s (%s)\n", m.Key(), r.Schema, formatResult(m))
+		}
+	}
+
+	if n > 0 {
+		fmt.Fprintln(w)
+	}
+
+	switch {
+	case n == 0:
+		fmt.Fprintln(w, "No migrations to run in any tenant.")
+	case isDryRun:
+		fmt.Fprintf(w, "%d pending migrations across %d tenants (dry run)\n", n, len(tenants))
+	default:
+		fmt.Fprintf(
+			w, "Ran %d migrations across %d tenants in %s\n",
+			n, len(tenants), formatDuration(total),
+		)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d tenants failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// parseTarget parses the --to flag value as either a migration version or
+// an RFC 3339 timestamp.
+func parseTarget(s string) (conduitversion.Version, time.Time, error) {
+	if s == "" {
+		return conduitversion.Version{}, time.Time{}, nil
+	}
+
+	if v, err := conduitversion.Parse(s); err == nil {
+		return v, time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return conduitversion.Version{}, time.Time{}, fmt.Errorf(
+			"invalid --%s value %q, expected a version (YYYYMMDDHHMMSS) or an RFC 3339 timestamp",
+			toFlag,
+			s,
+		)
+	}
+
+	return conduitversion.Version{}, t, nil
+}
+
+// formatResult formats the duration of a migration run, and the number of
+// attempts it took when it was retried.
+func formatResult(m *conduit.MigrationResult) string {
+	if len(m.Attempts) > 1 {
+		return fmt.Sprintf("%s, %d attempts", formatDuration(m.DurationTotal), len(m.Attempts))
+	}
+
+	return formatDuration(m.DurationTotal)
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
 		return fmt.Sprintf("%dms", d.Milliseconds())
@@ -0,0 +1,85 @@
+package baseline
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+const toFlag = "to"
+
+func NewCommand(
+	fs afero.Fs,
+	_ io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "baseline",
+		Usage: "record all migrations up to a version as applied without running them",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+
+			//nolint:exhaustruct
+			&cli.StringFlag{
+				Name:     toFlag,
+				Usage:    "version (YYYYMMDDHHMMSS) of the last migration the existing schema already contains",
+				Required: true,
+			},
+
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+			cmdutil.LockTimeoutFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			to, err := conduitversion.Parse(cmd.String(toFlag))
+			if err != nil {
+				return fmt.Errorf("failed to parse --%s: %w", toFlag, err)
+			}
+
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			migrator := conduit.NewMigrator(
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+				conduit.WithLockTimeout(cmd.Duration(cmdutil.LockTimeout)),
+			)
+
+			marked, err := conduitcli.Baseline(ctx, migrator, conduitcli.BaselineArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+				To:          to,
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			for _, key := range marked {
+				fmt.Fprintf(stderr, "Marked %s as applied\n", key)
+			}
+
+			fmt.Fprintf(stderr, "Baselined %d migrations\n", len(marked))
+
+			return nil
+		},
+	}
+}
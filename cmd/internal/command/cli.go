@@ -10,11 +10,19 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"go.inout.gg/conduit/cmd/internal/command/apply"
+	"go.inout.gg/conduit/cmd/internal/command/baseline"
 	"go.inout.gg/conduit/cmd/internal/command/diff"
+	"go.inout.gg/conduit/cmd/internal/command/drift"
 	"go.inout.gg/conduit/cmd/internal/command/dump"
+	"go.inout.gg/conduit/cmd/internal/command/history"
 	"go.inout.gg/conduit/cmd/internal/command/initialise"
+	"go.inout.gg/conduit/cmd/internal/command/lock"
+	"go.inout.gg/conduit/cmd/internal/command/mark"
 	"go.inout.gg/conduit/cmd/internal/command/new"
 	"go.inout.gg/conduit/cmd/internal/command/rehash"
+	"go.inout.gg/conduit/cmd/internal/command/repair"
+	"go.inout.gg/conduit/cmd/internal/command/resolve"
+	"go.inout.gg/conduit/cmd/internal/command/status"
 	"go.inout.gg/conduit/internal/cmdutil"
 	"go.inout.gg/conduit/pkg/conduitbuildinfo"
 	"go.inout.gg/conduit/pkg/stopwatch"
@@ -56,8 +64,16 @@ func Execute(
 			new.NewCommand(fs, stdout, stderr, timeGen, configSrc),
 			diff.NewCommand(fs, stdout, stderr, timeGen, bi, configSrc),
 			apply.NewCommand(fs, stdout, stderr, timer, configSrc),
+			status.NewCommand(fs, stdout, stderr, configSrc),
+			history.NewCommand(stdout, stderr, configSrc),
+			drift.NewCommand(fs, stdout, stderr, configSrc),
+			lock.NewCommand(stdout, stderr, configSrc),
+			mark.NewCommand(fs, stdout, stderr, configSrc),
+			baseline.NewCommand(fs, stdout, stderr, configSrc),
 			dump.NewCommand(stdout, bi, configSrc),
 			rehash.NewCommand(fs, stdout, stderr, configSrc),
+			repair.NewCommand(fs, stdout, stderr, configSrc),
+			resolve.NewCommand(fs, stdout, stderr, configSrc),
 		},
 	}
 
@@ -233,6 +233,146 @@ func TestApply(t *testing.T) {
 	})
 }
 
+func TestApply_TemplateVars(t *testing.T) {
+	t.Parallel()
+
+	writeTemplate := func(t *testing.T, fs afero.Fs) {
+		t.Helper()
+
+		require.NoError(t, afero.WriteFile(
+			fs, "migrations/20240116000000_create_users.up.sql",
+			[]byte("---- template ----\nCREATE TABLE {{ ident .schema \"users\" }} (id int);"), 0o644,
+		))
+	}
+
+	t.Run("should render templated migrations, when template variables are set", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		fs := afero.NewMemMapFs()
+		dbURL := testutil.ConnString(pool)
+
+		bootstrap(t, fs, dbURL)
+		writeTemplate(t, fs)
+
+		_, err := exec(t, fs, "conduit apply --database-url "+dbURL+" --template-var schema=public up")
+
+		require.NoError(t, err)
+		assert.True(t, testutil.TableExists(t, pool, "users"))
+	})
+
+	t.Run("should return error naming file, when template variable is missing", func(t *testing.T) {
+		t.Parallel()
+
+		fs := afero.NewMemMapFs()
+		writeTemplate(t, fs)
+
+		_, err := exec(t, fs, "conduit apply --database-url postgres://localhost/conduit up")
+
+		require.ErrorContains(t, err, "failed to load migrations")
+		require.ErrorContains(t, err, "20240116000000_create_users.up.sql")
+	})
+}
+
+func TestApply_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// The query has no spaces, as exec splits arguments on them.
+	const tenantsQuery = "SELECT(unnest('{tenant_a,tenant_b}'::text[]))"
+
+	newTenants := func(t *testing.T) (afero.Fs, string) {
+		t.Helper()
+
+		pool := poolFactory.Pool(t)
+		fs := afero.NewMemMapFs()
+		dbURL := testutil.ConnString(pool)
+
+		bootstrap(t, fs, dbURL)
+		testutil.Exec(t, pool, "CREATE SCHEMA tenant_a; CREATE SCHEMA tenant_b;")
+
+		return fs, dbURL
+	}
+
+	t.Run("should migrate every tenant, when tenants query is set", func(t *testing.T) {
+		t.Parallel()
+
+		fs, dbURL := newTenants(t)
+
+		r, err := exec(t, fs, "conduit apply --database-url "+dbURL+" --tenants-query "+tenantsQuery+" up")
+
+		require.NoError(t, err)
+		assert.Contains(t, r.stderr.String(), "Applied 20240115123045_conduit_initial_schema in tenant_a")
+		assert.Contains(t, r.stderr.String(), "Applied 20240115123045_conduit_initial_schema in tenant_b")
+		assert.Contains(t, r.stderr.String(), "Ran 2 migrations across 2 tenants")
+	})
+
+	t.Run("should preview one tenant at a time, when dry-run is enabled", func(t *testing.T) {
+		t.Parallel()
+
+		fs, dbURL := newTenants(t)
+
+		r, err := exec(
+			t, fs,
+			"conduit apply --database-url "+dbURL+" --tenants-query "+tenantsQuery+
+				" --tenant-concurrency 2 --dry-run up",
+		)
+
+		require.NoError(t, err)
+		assert.Contains(t, r.stderr.String(), "Previewing one tenant at a time: --tenant-concurrency is ignored")
+		assert.Contains(t, r.stderr.String(), "2 pending migrations across 2 tenants (dry run)")
+	})
+
+	t.Run("should return error, when tenants query is used with redo", func(t *testing.T) {
+		t.Parallel()
+
+		fs, dbURL := newTenants(t)
+
+		_, err := exec(t, fs, "conduit apply --database-url "+dbURL+" --tenants-query "+tenantsQuery+" redo")
+
+		require.ErrorContains(t, err, "--tenants-query cannot be used with redo")
+	})
+
+	t.Run("should report every tenant, when status is run with tenants query", func(t *testing.T) {
+		t.Parallel()
+
+		fs, dbURL := newTenants(t)
+
+		r, err := exec(t, fs, "conduit status --database-url "+dbURL+" --tenants-query "+tenantsQuery)
+
+		require.NoError(t, err)
+		assert.Contains(t, r.stdout.String(), "tenant_a")
+		assert.Contains(t, r.stdout.String(), "tenant_b")
+		assert.Equal(t, "2 tenants, 2 behind\n", r.stderr.String())
+	})
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should list migrations with their state", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		fs := afero.NewMemMapFs()
+		dbURL := testutil.ConnString(pool)
+
+		bootstrap(t, fs, dbURL)
+
+		r, err := exec(t, fs, "conduit status --database-url "+dbURL)
+		require.NoError(t, err)
+		assert.Contains(t, r.stdout.String(), "pending")
+		assert.Equal(t, "0 applied, 1 pending, 0 missing\n", r.stderr.String())
+
+		_, err = exec(t, fs, "conduit apply --database-url "+dbURL+" up")
+		require.NoError(t, err)
+
+		r, err = exec(t, fs, "conduit status --database-url "+dbURL)
+		require.NoError(t, err)
+		assert.Contains(t, r.stdout.String(), "applied")
+		assert.Equal(t, "1 applied, 0 pending, 0 missing\n", r.stderr.String())
+	})
+}
+
 func TestDump(t *testing.T) {
 	t.Parallel()
 
@@ -48,6 +48,9 @@ func NewCommand(
 			cmdutil.ExcludeSchemasFlag(src),
 			cmdutil.DatabaseURLFlag(src),
 			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			name := cmd.Args().First()
@@ -62,7 +65,10 @@ func NewCommand(
 				Name:                 name,
 				SchemaPath:           cmd.String(schemaFlag),
 				DatabaseURL:          cmd.String(cmdutil.DatabaseURL),
+				HistorySchema:        cmd.String(cmdutil.HistorySchema),
+				HistoryTable:         cmd.String(cmdutil.HistoryTable),
 				ExcludeSchemas:       cmd.StringSlice(cmdutil.ExcludeSchemas),
+				TemplateVars:         cmdutil.TemplateVars(cmd),
 				SkipSchemaDriftCheck: cmd.Bool(cmdutil.SkipSchemaDriftCheck),
 			}
 
@@ -0,0 +1,108 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+)
+
+func NewCommand(
+	fs afero.Fs,
+	stdout io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "drift",
+		Usage: "show how the database schema differs from the applied migrations",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.ExcludeSchemasFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			migrator := conduit.NewMigrator(
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithExcludeSchemas(cmd.StringSlice(cmdutil.ExcludeSchemas)...),
+			)
+
+			report, err := conduitcli.Drift(ctx, migrator, conduitcli.DriftArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			for _, key := range report.Skipped {
+				fmt.Fprintf(stderr, "Skipped %s: it cannot be replayed, objects it creates show as unexpected\n", key)
+			}
+
+			if !report.HasDrift() {
+				fmt.Fprintln(stderr, "No schema drift")
+				return nil
+			}
+
+			if err := displayReport(stdout, report); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("%w: %d objects differ", conduit.ErrSchemaDrift, len(report.Objects))
+		},
+	}
+}
+
+func displayReport(w io.Writer, report *conduit.DriftReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CHANGE\tKIND\tOBJECT")
+
+	for _, o := range report.Objects {
+		name := o.Name
+		if name == "" {
+			name = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", o.Change, o.Kind, name)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write drift report: %w", err)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "-- DDL that reconciles the database with the migrations:")
+
+	for _, stmt := range report.Statements {
+		fmt.Fprintln(w)
+
+		for _, hazard := range stmt.Hazards {
+			fmt.Fprintf(w, "---- hazard: %s // %s ----\n", hazard.Type, hazard.Message)
+		}
+
+		fmt.Fprintln(w, stmt.ToSQL())
+	}
+
+	return nil
+}
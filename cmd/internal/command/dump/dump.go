@@ -29,10 +29,14 @@ func NewCommand(w io.Writer, bi conduitbuildinfo.BuildInfo, src altsrc.Sourcer)
 				Required: true,
 			},
 			cmdutil.ExcludeSchemasFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return conduitcli.Dump(ctx, w, bi, conduitcli.DumpArgs{
 				DatabaseURL:    cmd.String(cmdutil.DatabaseURL),
+				HistorySchema:  cmd.String(cmdutil.HistorySchema),
+				HistoryTable:   cmd.String(cmdutil.HistoryTable),
 				ExcludeSchemas: cmd.StringSlice(cmdutil.ExcludeSchemas),
 			})
 		},
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+)
+
+const (
+	limitFlag = "limit"
+
+	// defaultLimit is the number of entries shown when --limit is not set.
+	defaultLimit = 20
+)
+
+func NewCommand(
+	stdout io.Writer,
+	_ io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "history",
+		Usage: "show the log of every migration run, newest first",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+
+			//nolint:exhaustruct
+			&cli.IntFlag{
+				Name:  limitFlag,
+				Usage: "maximum number of entries to show; 0 shows every entry",
+				Value: defaultLimit,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			migrator := conduit.NewMigrator(
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+			)
+
+			entries, err := conduitcli.History(ctx, migrator, conduitcli.HistoryArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+				Limit:       int(cmd.Int(limitFlag)),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			return displayHistory(stdout, entries)
+		},
+	}
+}
+
+func displayHistory(w io.Writer, entries []*conduit.HistoryEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "STARTED AT\tMIGRATION\tDIRECTION\tOUTCOME\tDURATION\tUSER\tHOST\tCONDUIT\tHAZARDS\tERROR")
+
+	for _, e := range entries {
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.StartedAt.Local().Format(time.DateTime),
+			e.Key(),
+			e.Direction,
+			e.Outcome,
+			e.Duration,
+			orDash(e.DatabaseUser),
+			orDash(e.Hostname),
+			orDash(e.ConduitVersion),
+			orDash(strings.Join(e.AllowedHazards, ",")),
+			orDash(firstLine(e.Error)),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// firstLine returns the first line of s, as errors such as hazard reports
+// span several lines.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
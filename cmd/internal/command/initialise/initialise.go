@@ -43,6 +43,20 @@ func NewCommand(
 					cli.EnvVar("CONDUIT_DATABASE_URL"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  cmdutil.HistoryTable,
+				Usage: "table applied migrations are recorded in (default: conduit_migrations)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_HISTORY_TABLE"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  cmdutil.HistorySchema,
+				Usage: "PostgreSQL schema of the history table (default: resolved via search_path)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("CONDUIT_HISTORY_SCHEMA"),
+				),
+			},
 			&cli.StringSliceFlag{
 				Name:  cmdutil.ExcludeSchemas,
 				Usage: "PostgreSQL schemas to exclude",
@@ -58,6 +72,8 @@ func NewCommand(
 				ConfigName:     "conduit.yaml",
 				MigrationsDir:  filepath.Clean(cmd.String(cmdutil.MigrationsDir)),
 				DatabaseURL:    cmd.String(cmdutil.DatabaseURL),
+				HistorySchema:  cmd.String(cmdutil.HistorySchema),
+				HistoryTable:   cmd.String(cmdutil.HistoryTable),
 				ExcludeSchemas: cmd.StringSlice(cmdutil.ExcludeSchemas),
 			}
 
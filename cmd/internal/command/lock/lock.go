@@ -0,0 +1,136 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/cmdutil"
+)
+
+// maxQueryLen is the number of query characters shown per session.
+const maxQueryLen = 60
+
+func NewCommand(
+	stdout io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "lock",
+		Usage: "inspect the migration advisory lock",
+		Commands: []*cli.Command{
+			newStatusCommand(stdout, stderr, src),
+		},
+	}
+}
+
+func newStatusCommand(
+	stdout io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "status",
+		Usage: "show the session holding the migration lock and the sessions waiting for it",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			migrator := conduit.NewMigrator(
+				conduit.WithRegistry(conduitregistry.New()),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+			)
+
+			status, err := conduitcli.LockStatus(ctx, migrator, conduitcli.LockStatusArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			if status.Holder == nil {
+				fmt.Fprintf(stderr, "Lock %d is free.\n", status.Key)
+
+				return nil
+			}
+
+			return displaySessions(stdout, status)
+		},
+	}
+}
+
+func displaySessions(w io.Writer, status *conduit.LockStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "LOCK\tPID\tUSER\tAPPLICATION\tSTATE\tQUERY START\tQUERY")
+
+	sessions := append([]*conduit.LockSession{status.Holder}, status.Waiters...)
+	for _, s := range sessions {
+		lock := "waiting"
+		if s.Granted {
+			lock = "held"
+		}
+
+		fmt.Fprintf(
+			tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			lock,
+			s.PID,
+			orDash(s.User),
+			orDash(s.ApplicationName),
+			orDash(s.State),
+			formatTime(s.QueryStart),
+			orDash(formatQuery(s.Query)),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write lock status: %w", err)
+	}
+
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// formatQuery collapses q onto a single line of at most maxQueryLen
+// characters.
+func formatQuery(q string) string {
+	q = strings.Join(strings.Fields(q), " ")
+	if len(q) > maxQueryLen {
+		return q[:maxQueryLen-3] + "..."
+	}
+
+	return q
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,120 @@
+package mark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+var errVersionMissing = errors.New("version argument is required")
+
+type markFunc func(context.Context, *conduit.Migrator, conduitcli.MarkArgs) ([]string, error)
+
+func NewCommand(
+	fs afero.Fs,
+	stdout io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "mark",
+		Usage: "record migrations as applied or unapplied without running them",
+		Commands: []*cli.Command{
+			newSubcommand(
+				fs, stderr, src,
+				"applied", "record migrations of a version as applied without running them",
+				"Marked %s as applied\n", conduitcli.MarkApplied,
+			),
+			newSubcommand(
+				fs, stderr, src,
+				"unapplied", "remove history rows of a version without running down migrations",
+				"Marked %s as unapplied\n", conduitcli.MarkUnapplied,
+			),
+		},
+	}
+}
+
+func newSubcommand(
+	fs afero.Fs,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+	name, usage, format string,
+	mark markFunc,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		ArgsUsage: "<version>",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+			cmdutil.LockTimeoutFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if !cmd.Args().Present() {
+				return errVersionMissing
+			}
+
+			version, err := conduitversion.Parse(cmd.Args().First())
+			if err != nil {
+				return fmt.Errorf("failed to parse version: %w", err)
+			}
+
+			migrator, err := newMigrator(fs, cmd)
+			if err != nil {
+				return err
+			}
+
+			marked, err := mark(ctx, migrator, conduitcli.MarkArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+				Version:     version,
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			if len(marked) == 0 {
+				fmt.Fprintln(stderr, "Nothing to mark.")
+			}
+
+			for _, key := range marked {
+				fmt.Fprintf(stderr, format, key)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newMigrator(fs afero.Fs, cmd *cli.Command) (*conduit.Migrator, error) {
+	registry, err := cmdutil.Registry(fs, cmd)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	return conduit.NewMigrator(
+		conduit.WithRegistry(registry),
+		conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+		conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+		conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+		conduit.WithLockTimeout(cmd.Duration(cmdutil.LockTimeout)),
+	), nil
+}
@@ -16,6 +16,8 @@ import (
 	"go.inout.gg/conduit/pkg/timegenerator"
 )
 
+const repeatableFlag = "repeatable"
+
 //nolint:revive
 func NewCommand(
 	fs afero.Fs,
@@ -31,6 +33,12 @@ func NewCommand(
 		ArgsUsage: "<name>",
 		Flags: []cli.Flag{
 			cmdutil.MigrationsDirFlag(src),
+
+			//nolint:exhaustruct
+			&cli.BoolFlag{
+				Name:  repeatableFlag,
+				Usage: "create a repeatable migration, reapplied whenever its content changes",
+			},
 		},
 		Action: func(_ context.Context, cmd *cli.Command) error {
 			name := cmd.Args().First()
@@ -41,13 +49,17 @@ func NewCommand(
 			result, err := conduitcli.New(fs, timeGen, conduitcli.NewArgs{
 				MigrationsDir: cmd.String(cmdutil.MigrationsDir),
 				Name:          name,
+				Repeatable:    cmd.Bool(repeatableFlag),
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create migration: %w", err)
 			}
 
 			fmt.Fprintf(stderr, "Created %s\n", result.UpFile)
-			fmt.Fprintf(stderr, "Created %s\n", result.DownFile)
+
+			if result.DownFile != "" {
+				fmt.Fprintf(stderr, "Created %s\n", result.DownFile)
+			}
 
 			return nil
 		},
@@ -29,6 +29,9 @@ func NewCommand(
 			cmdutil.ExcludeSchemasFlag(src),
 			cmdutil.DatabaseURLFlag(src),
 			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			store := hashsum.NewFSStore(fs, "conduit.sum")
@@ -36,7 +39,10 @@ func NewCommand(
 				RootDir:        ".",
 				MigrationsDir:  filepath.Clean(cmd.String(cmdutil.MigrationsDir)),
 				DatabaseURL:    cmd.String(cmdutil.DatabaseURL),
+				HistorySchema:  cmd.String(cmdutil.HistorySchema),
+				HistoryTable:   cmd.String(cmdutil.HistoryTable),
 				ExcludeSchemas: cmd.StringSlice(cmdutil.ExcludeSchemas),
+				TemplateVars:   cmdutil.TemplateVars(cmd),
 			}
 
 			if err := conduitcli.Rehash(ctx, fs, store, args); err != nil {
@@ -0,0 +1,74 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+)
+
+func NewCommand(
+	fs afero.Fs,
+	_ io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:      "repair",
+		Usage:     "accept the current content of modified applied migrations",
+		ArgsUsage: "[<version>_<name>...]",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+			cmdutil.LockTimeoutFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			migrator := conduit.NewMigrator(
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+				conduit.WithLockTimeout(cmd.Duration(cmdutil.LockTimeout)),
+			)
+
+			repaired, err := conduitcli.Repair(ctx, migrator, conduitcli.RepairArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+				Migrations:  cmd.Args().Slice(),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			if len(repaired) == 0 {
+				fmt.Fprintln(stderr, "Nothing to repair.")
+
+				return nil
+			}
+
+			for _, key := range repaired {
+				fmt.Fprintf(stderr, "Repaired %s\n", key)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/internal/cmdutil"
+)
+
+func NewCommand(
+	fs afero.Fs,
+	_ io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "resolve",
+		Usage: "record a migration that failed part-way as completed after fixing it by hand",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.LockKeyFlag(src),
+			cmdutil.LockTimeoutFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			migrator := conduit.NewMigrator(
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithLockKey(cmd.String(cmdutil.LockKey)),
+				conduit.WithLockTimeout(cmd.Duration(cmdutil.LockTimeout)),
+			)
+
+			key, err := conduitcli.Resolve(ctx, migrator, conduitcli.ResolveArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			fmt.Fprintf(stderr, "Resolved %s\n", key)
+
+			return nil
+		},
+	}
+}
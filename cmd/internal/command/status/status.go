@@ -0,0 +1,210 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/afero"
+	altsrc "github.com/urfave/cli-altsrc/v3"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitcli"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/cmdutil"
+	"go.inout.gg/conduit/internal/sliceutil"
+)
+
+// shortHashLen is the number of schema hash characters shown in the table.
+const shortHashLen = 12
+
+func NewCommand(
+	fs afero.Fs,
+	stdout io.Writer,
+	stderr io.Writer,
+	src altsrc.Sourcer,
+) *cli.Command {
+	//nolint:exhaustruct
+	return &cli.Command{
+		Name:  "status",
+		Usage: "show applied, pending and missing migrations",
+		Flags: []cli.Flag{
+			cmdutil.DatabaseURLFlag(src),
+			cmdutil.MigrationsDirFlag(src),
+			cmdutil.TemplateVarFlag(src),
+			cmdutil.HistorySchemaFlag(src),
+			cmdutil.HistoryTableFlag(src),
+			cmdutil.TagsFlag(src),
+			cmdutil.ExcludeTagsFlag(src),
+			cmdutil.TenantsQueryFlag(src),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			registry, err := cmdutil.Registry(fs, cmd)
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			opts := []conduit.Option{
+				conduit.WithRegistry(registry),
+				conduit.WithHistorySchema(cmd.String(cmdutil.HistorySchema)),
+				conduit.WithHistoryTable(cmd.String(cmdutil.HistoryTable)),
+				conduit.WithTags(conduit.TagFilter{
+					Include: cmd.StringSlice(cmdutil.Tags),
+					Exclude: cmd.StringSlice(cmdutil.ExcludeTags),
+				}),
+			}
+
+			if tenantsQuery := cmd.String(cmdutil.TenantsQuery); tenantsQuery != "" {
+				statuses, err := conduitcli.TenantsStatus(
+					ctx,
+					conduit.NewTenantRunner(opts),
+					conduitcli.TenantsStatusArgs{
+						DatabaseURL:  cmd.String(cmdutil.DatabaseURL),
+						TenantsQuery: tenantsQuery,
+					},
+				)
+				if err != nil {
+					//nolint:wrapcheck
+					return err
+				}
+
+				if err := displayTenants(stdout, statuses); err != nil {
+					return err
+				}
+
+				behind := sliceutil.Filter(statuses, func(s *conduit.TenantStatus) bool {
+					return len(s.Report.Pending()) > 0 || len(s.Report.Dirty()) > 0
+				})
+				fmt.Fprintf(stderr, "%d tenants, %d behind\n", len(statuses), len(behind))
+
+				return nil
+			}
+
+			migrator := conduit.NewMigrator(opts...)
+
+			report, err := conduitcli.Status(ctx, migrator, conduitcli.StatusArgs{
+				DatabaseURL: cmd.String(cmdutil.DatabaseURL),
+			})
+			if err != nil {
+				//nolint:wrapcheck
+				return err
+			}
+
+			if err := displayReport(stdout, report); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(
+				stderr, "%d applied, %d pending, %d missing",
+				len(report.Applied()), len(report.Pending()), len(report.Missing()),
+			)
+
+			if skipped := report.Skipped(); len(skipped) > 0 {
+				fmt.Fprintf(stderr, ", %d skipped by tags", len(skipped))
+			}
+
+			fmt.Fprintln(stderr)
+
+			for _, s := range report.Dirty() {
+				fmt.Fprintf(
+					stderr, "%s is dirty: %s stopped after %d statements\n",
+					s.Key(), s.DirtyDirection, s.LastStatement,
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+func displayReport(w io.Writer, report *conduit.StatusReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "STATE\tMIGRATION\tAPPLIED AT\tTX\tHASH\tHAZARDS")
+
+	for _, s := range report.Migrations {
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.State,
+			s.Key(),
+			formatTime(s.AppliedAt),
+			formatTx(s),
+			formatHash(s.Hash),
+			formatHazards(s.Hazards),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+
+	return nil
+}
+
+// displayTenants prints one row per tenant with the number of its
+// migrations in each state.
+func displayTenants(w io.Writer, statuses []*conduit.TenantStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TENANT\tAPPLIED\tPENDING\tSKIPPED\tMISSING\tDIRTY")
+
+	for _, s := range statuses {
+		fmt.Fprintf(
+			tw, "%s\t%d\t%d\t%d\t%d\t%d\n",
+			s.Schema,
+			len(s.Report.Applied()),
+			len(s.Report.Pending()),
+			len(s.Report.Skipped()),
+			len(s.Report.Missing()),
+			len(s.Report.Dirty()),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.DateTime)
+}
+
+func formatTx(s *conduit.MigrationStatus) string {
+	switch {
+	case s.Migration == nil:
+		return "-"
+	case s.UseTx:
+		return "yes"
+	default:
+		return "no"
+	}
+}
+
+func formatHash(h string) string {
+	if h == "" {
+		return "-"
+	}
+
+	return h[:min(shortHashLen, len(h))]
+}
+
+func formatHazards(hazards []conduitregistry.Hazard) string {
+	if len(hazards) == 0 {
+		return "-"
+	}
+
+	return strings.Join(sliceutil.Map(hazards, func(h conduitregistry.Hazard) string {
+		return h.Type
+	}), ",")
+}
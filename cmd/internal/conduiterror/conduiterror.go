@@ -23,12 +23,43 @@ func Display(w io.Writer, err error) {
 	switch {
 	case errors.Is(err, conduit.ErrSchemaDrift):
 		hint = "the database schema was modified outside of migrations (manual DDL).\n" +
-			"Run 'conduit diff' to generate a migration that captures the changes.\n" +
+			"Run 'conduit drift' to see which objects differ, and 'conduit diff' to generate a migration that captures the changes.\n" +
 			"To skip this check: --skip-schema-drift-check"
+	case errors.Is(err, conduit.ErrSchemaVerification):
+		hint = "the migration left the schema in a different state than replaying the migrations does, e.g. because it depends on data or extensions.\n" +
+			"The migration is already recorded. Review the statements above, then fix the schema with a new migration.\n" +
+			"To run without verification: omit --verify-schema"
 	case errors.Is(err, conduit.ErrHazardDetected):
 		hint = "these operations can cause table locks, downtime, or irreversible data loss in production.\n" +
 			"Review each hazard above before proceeding.\n" +
 			"To explicitly allow specific types: --allow-hazards <TYPE>"
+	case errors.Is(err, conduit.ErrLockTimeout):
+		hint = "another session is running migrations or still holds the migration lock.\n" +
+			"Run 'conduit lock status' to see which session holds it.\n" +
+			"To wait longer: --lock-timeout <duration>"
+	case errors.Is(err, conduit.ErrDirtyMigration):
+		hint = "a migration that runs outside a transaction failed part-way and left the schema half-migrated.\n" +
+			"Fix the failed statement, then run 'conduit apply resume' to continue from it.\n" +
+			"If you finished or reverted the migration by hand: conduit resolve, or conduit mark unapplied <version>"
+	case errors.Is(err, conduit.ErrNoMigrationLog):
+		hint = "the database was initialised before conduit kept a migration log.\n" +
+			"Add a migration that creates the log table; see the conduit_migrations_log table in a fresh 'conduit init'.\n" +
+			"Migrations keep running without it, they are just not logged"
+	case errors.Is(err, conduit.ErrChecksumMismatch):
+		hint = "these migrations were edited after they had been applied; the edits never ran against this database.\n" +
+			"Revert the edits and write a new migration instead.\n" +
+			"To accept the edited content as applied: conduit repair <version>_<name>"
+	case errors.Is(err, conduit.ErrUnmetDependency):
+		hint = "a depends-on directive requires the migrations to run in a different order than requested.\n" +
+			"Include the dependencies with a larger --steps or a later --to, or roll back the dependent migrations first"
+	case errors.Is(err, conduit.ErrOutOfOrder):
+		hint = "these migrations were added after newer ones had already been applied, e.g. by a branch merge.\n" +
+			"Check they do not depend on the order of the newer migrations, or give them a newer version.\n" +
+			"To apply them anyway: --out-of-order allow"
+	case errors.Is(err, conduit.ErrOrphanedMigrations):
+		hint = "the history table records migrations whose files no longer exist.\n" +
+			"Restore the missing files, or remove the rows once you have confirmed they are obsolete.\n" +
+			"To proceed anyway: --orphaned allow"
 	}
 
 	fmt.Fprintf(w, "Error: %s\n", err)
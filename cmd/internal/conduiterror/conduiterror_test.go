@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gkampitakis/go-snaps/snaps"
+	schemadiff "github.com/stripe/pg-schema-diff/pkg/diff"
 
 	"go.inout.gg/conduit"
 	"go.inout.gg/conduit/cmd/internal/conduiterror"
+	"go.inout.gg/conduit/pkg/conduitversion"
+	"go.inout.gg/conduit/pkg/pgdiff"
 )
 
 func TestDisplay(t *testing.T) {
@@ -27,6 +31,45 @@ func TestDisplay(t *testing.T) {
 			name: "schema drift",
 			err:  fmt.Errorf("%w: expected hash abc, got xyz", conduit.ErrSchemaDrift),
 		},
+		{
+			name: "schema drift report",
+			err: &conduit.SchemaDriftError{
+				Report: &conduit.DriftReport{
+					DriftReport: pgdiff.DriftReport{
+						Objects: []pgdiff.DriftedObject{
+							{Kind: pgdiff.ObjectKindTable, Name: "public.audit", Change: pgdiff.ObjectChangeUnexpected},
+							{Kind: pgdiff.ObjectKindColumn, Name: "public.users.email", Change: pgdiff.ObjectChangeMissing},
+						},
+					},
+				},
+				Expected: "abc",
+				Actual:   "xyz",
+			},
+		},
+		{
+			name: "schema verification failed",
+			err: &conduit.SchemaVerificationError{
+				Report: &conduit.DriftReport{
+					DriftReport: pgdiff.DriftReport{
+						Objects: []pgdiff.DriftedObject{
+							{Kind: pgdiff.ObjectKindIndex, Name: "public.users_email_idx", Change: pgdiff.ObjectChangeUnexpected},
+						},
+						Statements: []schemadiff.Statement{
+							{DDL: `DROP INDEX CONCURRENTLY "public"."users_email_idx"`},
+						},
+					},
+				},
+				Key:       "20250101120000_index_users",
+				Direction: conduit.DirectionUp,
+			},
+		},
+		{
+			name: "unmet dependency",
+			err: fmt.Errorf(
+				"%w: 20250101120000_add_posts_fk depends on shield/20250102120000_create_users, which is not applied",
+				conduit.ErrUnmetDependency,
+			),
+		},
 		{
 			name: "hazard detected",
 			err: fmt.Errorf(
@@ -48,6 +91,37 @@ func TestDisplay(t *testing.T) {
 				fmt.Errorf("%w: migration foo contains hazards", conduit.ErrHazardDetected),
 			),
 		},
+		{
+			name: "out of order",
+			err: &conduit.OutOfOrderError{
+				Migrations:    []string{"20250101000000_foo"},
+				LatestApplied: conduitversion.NewFromTime(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name: "lock timeout",
+			err:  fmt.Errorf("%w after 5s: held by pid 42", conduit.ErrLockTimeout),
+		},
+		{
+			name: "dirty migration",
+			err: &conduit.DirtyMigrationError{
+				Migration:     "20250101000000_foo",
+				Direction:     conduit.DirectionUp,
+				LastStatement: 2,
+			},
+		},
+		{
+			name: "no migration log",
+			err:  fmt.Errorf("%w: \"conduit_migrations_log\"", conduit.ErrNoMigrationLog),
+		},
+		{
+			name: "checksum mismatch",
+			err:  &conduit.ChecksumMismatchError{Migrations: []string{"20250101000000_foo"}},
+		},
+		{
+			name: "orphaned migrations",
+			err:  &conduit.OrphanedMigrationsError{Migrations: []string{"20250101000000_foo"}},
+		},
 	}
 
 	for _, tt := range tests {
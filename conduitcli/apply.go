@@ -5,18 +5,24 @@ import (
 	"context"
 	"fmt"
 	"iter"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
 	"go.inout.gg/conduit"
 	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/pkg/conduitversion"
 )
 
 // ApplyArgs configures an [Apply] operation.
+//
+// Direction may be empty when To or ToTime is set, see [conduit.MigrateOptions].
 type ApplyArgs struct {
+	ToTime       time.Time
 	DatabaseURL  string
 	Direction    direction.Direction
 	AllowHazards []conduit.HazardType
+	To           conduitversion.Version
 	Steps        int
 }
 
@@ -36,6 +42,8 @@ func Apply(
 	seq, err := migrator.Migrate(ctx, args.Direction, conn, &conduit.MigrateOptions{
 		Steps:        args.Steps,
 		AllowHazards: args.AllowHazards,
+		To:           args.To,
+		ToTime:       args.ToTime,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to apply migrations: %w", err)
@@ -33,7 +33,10 @@ type DiffArgs struct {
 	Name                 string
 	SchemaPath           string
 	DatabaseURL          string
+	HistorySchema        string
+	HistoryTable         string
 	ExcludeSchemas       []string
+	TemplateVars         map[string]any
 	SkipSchemaDriftCheck bool
 }
 
@@ -71,7 +74,16 @@ func Diff(
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
-	plan, err := pgdiff.GeneratePlan(ctx, fs, connConfig, args.MigrationsDir, args.SchemaPath, args.ExcludeSchemas)
+	plan, err := pgdiff.GeneratePlan(
+		ctx,
+		fs,
+		connConfig,
+		args.MigrationsDir,
+		args.SchemaPath,
+		args.ExcludeSchemas,
+		pgdiff.WithHistoryTable(args.HistorySchema, args.HistoryTable),
+		pgdiff.WithTemplateVars(args.TemplateVars),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate diff plan: %w", err)
 	}
@@ -0,0 +1,51 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// ResumeArgs configures a [Resume] operation.
+type ResumeArgs struct {
+	DatabaseURL string
+}
+
+// Resume connects to the database and runs the remaining statements of the
+// dirty migration.
+func Resume(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args ResumeArgs,
+) (*conduit.MigrationResult, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) (*conduit.MigrationResult, error) {
+		result, err := migrator.Resume(ctx, conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resume migration: %w", err)
+		}
+
+		return result, nil
+	})
+}
+
+// ResolveArgs configures a [Resolve] operation.
+type ResolveArgs struct {
+	DatabaseURL string
+}
+
+// Resolve connects to the database and clears the dirty state, recording the
+// dirty migration as if its failed run had succeeded. It returns the key of
+// the resolved migration.
+func Resolve(ctx context.Context, migrator *conduit.Migrator, args ResolveArgs) (string, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) (string, error) {
+		key, err := migrator.Resolve(ctx, conn)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve migration: %w", err)
+		}
+
+		return key, nil
+	})
+}
@@ -0,0 +1,103 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestDirty(t *testing.T) {
+	t.Parallel()
+
+	fixed := map[string]string{
+		"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);\nSELECT 1;\nCREATE TABLE posts (id INT);",
+	}
+
+	// fail applies a failing version of create_users, leaving it dirty after
+	// its first statement.
+	fail := func(t *testing.T, dbURL string) {
+		t.Helper()
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);\nSELECT 1/0;\nCREATE TABLE posts (id INT);",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := Apply(t.Context(), m, ApplyArgs{DatabaseURL: dbURL, Direction: direction.DirectionUp})
+		require.NoError(t, err)
+
+		for _, err := range seq {
+			if err != nil {
+				return
+			}
+		}
+
+		t.Fatal("expected migration to fail")
+	}
+
+	t.Run("should run remaining statements, when dirty migration is resumed", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		fail(t, testutil.ConnString(pool))
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)), conduit.WithSkipSchemaDriftCheck())
+
+		result, err := Resume(t.Context(), m, ResumeArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.Equal(t, "20230601120000_create_users", result.Key())
+		assert.True(t, testutil.TableExists(t, pool, "posts"))
+	})
+
+	t.Run("should record migration as applied, when dirty migration is resolved", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		fail(t, testutil.ConnString(pool))
+		testutil.Exec(t, pool, "CREATE TABLE posts (id INT);")
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)), conduit.WithSkipSchemaDriftCheck())
+
+		key, err := Resolve(t.Context(), m, ResolveArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.Equal(t, "20230601120000_create_users", key)
+
+		report, err := Status(t.Context(), m, StatusArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.NoError(t, err)
+		assert.Empty(t, report.Dirty())
+		assert.Len(t, report.Applied(), 1)
+	})
+
+	t.Run("should return error, when nothing is dirty", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)), conduit.WithSkipSchemaDriftCheck())
+
+		_, err := Resume(t.Context(), m, ResumeArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.ErrorIs(t, err, conduit.ErrNotDirty)
+		require.ErrorContains(t, err, "failed to resume migration")
+
+		_, err = Resolve(t.Context(), m, ResolveArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.ErrorIs(t, err, conduit.ErrNotDirty)
+		require.ErrorContains(t, err, "failed to resolve migration")
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)))
+
+		_, err := Resume(t.Context(), m, ResumeArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
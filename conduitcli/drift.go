@@ -0,0 +1,32 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// DriftArgs configures a [Drift] operation.
+type DriftArgs struct {
+	DatabaseURL string
+}
+
+// Drift connects to the database and reports how its schema differs from
+// the schema the applied migrations describe.
+func Drift(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args DriftArgs,
+) (*conduit.DriftReport, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) (*conduit.DriftReport, error) {
+		report, err := migrator.Drift(ctx, conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect schema drift: %w", err)
+		}
+
+		return report, nil
+	})
+}
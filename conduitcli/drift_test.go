@@ -0,0 +1,78 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/pgdiff"
+)
+
+func TestDrift(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report drifted objects, when schema was changed by hand", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := Apply(t.Context(), m, ApplyArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Direction:   direction.DirectionUp,
+		})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+		testutil.Exec(t, pool, "CREATE TABLE manual (id INT);")
+
+		report, err := Drift(t.Context(), m, DriftArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.True(t, report.HasDrift())
+		assert.Equal(t, []pgdiff.DriftedObject{
+			{Kind: pgdiff.ObjectKindTable, Name: "public.manual", Change: pgdiff.ObjectChangeUnexpected},
+		}, report.Objects)
+	})
+
+	t.Run("should report no drift, when schema matches the migrations", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := Apply(t.Context(), m, ApplyArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Direction:   direction.DirectionUp,
+		})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		report, err := Drift(t.Context(), m, DriftArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := Drift(t.Context(), m, DriftArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
@@ -15,6 +15,8 @@ import (
 // DumpArgs configures a [Dump] operation.
 type DumpArgs struct {
 	DatabaseURL    string
+	HistorySchema  string
+	HistoryTable   string
 	ExcludeSchemas []string
 }
 
@@ -25,7 +27,12 @@ func Dump(ctx context.Context, w io.Writer, bi conduitbuildinfo.BuildInfo, args
 		return fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
-	stmts, err := pgdiff.DumpSchema(ctx, connConfig, args.ExcludeSchemas)
+	stmts, err := pgdiff.DumpSchema(
+		ctx,
+		connConfig,
+		args.ExcludeSchemas,
+		pgdiff.WithHistoryTable(args.HistorySchema, args.HistoryTable),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to dump schema: %w", err)
 	}
@@ -0,0 +1,33 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// HistoryArgs configures a [History] operation.
+type HistoryArgs struct {
+	DatabaseURL string
+	Limit       int
+}
+
+// History connects to the database and returns the latest entries of the
+// migration log, newest first.
+func History(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args HistoryArgs,
+) ([]*conduit.HistoryEntry, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) ([]*conduit.HistoryEntry, error) {
+		entries, err := migrator.History(ctx, conn, args.Limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration history: %w", err)
+		}
+
+		return entries, nil
+	})
+}
@@ -0,0 +1,53 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return latest runs newest first, when limit is set", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql":   "CREATE TABLE users (id INT);",
+			"20230601120000_create_users.down.sql": "DROP TABLE users;",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		for _, dir := range []direction.Direction{direction.DirectionUp, direction.DirectionDown} {
+			seq, err := Apply(t.Context(), m, ApplyArgs{DatabaseURL: testutil.ConnString(pool), Direction: dir})
+			require.NoError(t, err)
+			testutil.CollectSeq2(t, seq)
+		}
+
+		entries, err := History(t.Context(), m, HistoryArgs{DatabaseURL: testutil.ConnString(pool), Limit: 1})
+
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "20230601120000_create_users", entries[0].Key())
+		assert.Equal(t, direction.DirectionDown, entries[0].Direction)
+		assert.Equal(t, conduit.HistoryOutcomeSucceeded, entries[0].Outcome)
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := History(t.Context(), m, HistoryArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
@@ -24,6 +24,8 @@ type InitArgs struct {
 	ConfigName     string
 	MigrationsDir  string
 	DatabaseURL    string
+	HistorySchema  string
+	HistoryTable   string
 	ExcludeSchemas []string
 }
 
@@ -51,7 +53,9 @@ func Init(
 
 	migrationsFs := afero.NewBasePathFs(fs, migrationsPath)
 
-	migrationFilename, err := createInitialMigration(migrationsFs, timeGen)
+	table := migrations.NewTable(args.HistorySchema, args.HistoryTable)
+
+	migrationFilename, err := createInitialMigration(migrationsFs, timeGen, table)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +65,7 @@ func Init(
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
-	migrationStmts, err := migrationfile.ReadStmtsFromDir(fs, migrationsPath)
+	migrationStmts, err := migrationfile.ReadStmtsFromDir(fs, migrationsPath, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migration files: %w", err)
 	}
@@ -78,6 +82,8 @@ func Init(
 	if err := writeConfigFile(fs, args.RootDir, args.ConfigName, ConfigArgs{
 		MigrationsDir: args.MigrationsDir,
 		DatabaseURL:   args.DatabaseURL,
+		HistorySchema: args.HistorySchema,
+		HistoryTable:  args.HistoryTable,
 	}); err != nil {
 		return nil, err
 	}
@@ -93,6 +99,8 @@ func Init(
 type ConfigArgs struct {
 	MigrationsDir string
 	DatabaseURL   string
+	HistorySchema string
+	HistoryTable  string
 }
 
 func writeConfigFile(fs afero.Fs, dir string, name string, args ConfigArgs) error {
@@ -118,14 +126,18 @@ func createMigrationDir(fs afero.Fs, dir string) error {
 	return nil
 }
 
-func createInitialMigration(fs afero.Fs, timeGen timegenerator.Generator) (string, error) {
+func createInitialMigration(
+	fs afero.Fs,
+	timeGen timegenerator.Generator,
+	table migrations.Table,
+) (string, error) {
 	filename := conduitversion.MigrationFilename(
 		conduitversion.NewFromTime(timeGen.Now()),
 		"conduit_initial_schema",
 		conduitversion.MigrationDirectionUp,
 	)
 
-	if err := afero.WriteFile(fs, filename, migrations.Schema, 0o644); err != nil {
+	if err := afero.WriteFile(fs, filename, migrations.SchemaFor(table), 0o644); err != nil {
 		return "", fmt.Errorf("failed to create initial migration file: %w", err)
 	}
 
@@ -0,0 +1,36 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// LockStatusArgs configures a [LockStatus] operation.
+type LockStatusArgs struct {
+	DatabaseURL string
+}
+
+// LockStatus connects to the database and reports the sessions holding or
+// waiting for the migrator's advisory lock.
+func LockStatus(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args LockStatusArgs,
+) (*conduit.LockStatus, error) {
+	conn, err := pgx.Connect(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	status, err := migrator.LockStatus(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock status: %w", err)
+	}
+
+	return status, nil
+}
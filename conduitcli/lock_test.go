@@ -0,0 +1,70 @@
+package conduitcli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestLockStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report free lock, when no migration is running", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		status, err := LockStatus(t.Context(), m, LockStatusArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.Nil(t, status.Holder)
+		assert.Empty(t, status.Waiters)
+		assert.NotZero(t, status.Key)
+	})
+
+	t.Run("should report holder, when another session holds the lock", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		free, err := LockStatus(t.Context(), m, LockStatusArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.NoError(t, err)
+
+		conn, err := pgx.Connect(t.Context(), testutil.ConnString(pool))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close(context.Background()) })
+
+		_, err = conn.Exec(t.Context(), "SELECT pg_advisory_lock($1)", free.Key)
+		require.NoError(t, err)
+
+		status, err := LockStatus(t.Context(), m, LockStatusArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		require.NotNil(t, status.Holder)
+		assert.Equal(t, int32(conn.PgConn().PID()), status.Holder.PID)
+		assert.Empty(t, status.Waiters)
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := LockStatus(t.Context(), m, LockStatusArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
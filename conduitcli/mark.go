@@ -0,0 +1,73 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+// MarkArgs configures a [MarkApplied] or [MarkUnapplied] operation.
+type MarkArgs struct {
+	DatabaseURL string
+	Version     conduitversion.Version
+}
+
+// MarkApplied connects to the database and records the migrations of the
+// given version as applied without running them.
+func MarkApplied(ctx context.Context, migrator *conduit.Migrator, args MarkArgs) ([]string, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) ([]string, error) {
+		marked, err := migrator.MarkApplied(ctx, conn, args.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark migrations as applied: %w", err)
+		}
+
+		return marked, nil
+	})
+}
+
+// MarkUnapplied connects to the database and removes the history rows of
+// the given version without running the down migrations.
+func MarkUnapplied(ctx context.Context, migrator *conduit.Migrator, args MarkArgs) ([]string, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) ([]string, error) {
+		marked, err := migrator.MarkUnapplied(ctx, conn, args.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark migrations as unapplied: %w", err)
+		}
+
+		return marked, nil
+	})
+}
+
+// BaselineArgs configures a [Baseline] operation.
+type BaselineArgs struct {
+	DatabaseURL string
+	To          conduitversion.Version
+}
+
+// Baseline connects to the database and records every pending migration up
+// to and including To as applied without running them.
+func Baseline(ctx context.Context, migrator *conduit.Migrator, args BaselineArgs) ([]string, error) {
+	return withConn(ctx, args.DatabaseURL, func(conn *pgx.Conn) ([]string, error) {
+		marked, err := migrator.Baseline(ctx, conn, args.To)
+		if err != nil {
+			return nil, fmt.Errorf("failed to baseline: %w", err)
+		}
+
+		return marked, nil
+	})
+}
+
+func withConn[T any](ctx context.Context, databaseURL string, f func(*pgx.Conn) (T, error)) (T, error) {
+	conn, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	return f(conn)
+}
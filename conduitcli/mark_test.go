@@ -0,0 +1,145 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+func parseVersion(t *testing.T, s string) conduitversion.Version {
+	t.Helper()
+
+	v, err := conduitversion.Parse(s)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestMark(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+		"20230602120000_create_posts.up.sql": "CREATE TABLE posts (id INT);",
+	}
+
+	t.Run("should record migration without running it, when marked applied", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		marked, err := MarkApplied(t.Context(), m, MarkArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Version:     parseVersion(t, "20230602120000"),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230602120000_create_posts"}, marked)
+		assert.False(t, testutil.TableExists(t, pool, "posts"))
+
+		report, err := Status(t.Context(), m, StatusArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 1)
+	})
+
+	t.Run("should remove migration without rolling it back, when marked unapplied", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		_, err := MarkApplied(t.Context(), m, MarkArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Version:     parseVersion(t, "20230602120000"),
+		})
+		require.NoError(t, err)
+
+		marked, err := MarkUnapplied(t.Context(), m, MarkArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Version:     parseVersion(t, "20230602120000"),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230602120000_create_posts"}, marked)
+
+		report, err := Status(t.Context(), m, StatusArgs{DatabaseURL: testutil.ConnString(pool)})
+		require.NoError(t, err)
+		assert.Empty(t, report.Applied())
+	})
+
+	t.Run("should return error, when marked unapplied migration is not applied", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		_, err := MarkUnapplied(t.Context(), m, MarkArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Version:     parseVersion(t, "20230602120000"),
+		})
+
+		require.ErrorIs(t, err, conduit.ErrMigrationNotApplied)
+		require.ErrorContains(t, err, "failed to mark migrations as unapplied")
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		_, err := MarkApplied(t.Context(), m, MarkArgs{
+			DatabaseURL: "invalid://url",
+			Version:     parseVersion(t, "20230602120000"),
+		})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
+
+func TestBaseline(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+		"20230602120000_create_posts.up.sql": "CREATE TABLE posts (id INT);",
+		"20230603120000_create_tags.up.sql":  "CREATE TABLE tags (id INT);",
+	}
+
+	t.Run("should record pending migrations up to target, when target is known", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		marked, err := Baseline(t.Context(), m, BaselineArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			To:          parseVersion(t, "20230602120000"),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_users", "20230602120000_create_posts"}, marked)
+		assert.False(t, testutil.TableExists(t, pool, "users"))
+	})
+
+	t.Run("should return error, when target is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		_, err := Baseline(t.Context(), m, BaselineArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			To:          parseVersion(t, "20230602130000"),
+		})
+
+		require.ErrorIs(t, err, conduit.ErrUnknownTarget)
+		require.ErrorContains(t, err, "failed to baseline")
+	})
+}
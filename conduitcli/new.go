@@ -11,19 +11,24 @@ import (
 )
 
 // NewArgs configures a [New] operation.
+//
+// Repeatable creates a single repeatable migration file instead of a
+// versioned pair.
 type NewArgs struct {
 	MigrationsDir string
 	Name          string
+	Repeatable    bool
 }
 
-// NewResult holds the paths created by [New].
+// NewResult holds the paths created by [New]. DownFile is empty for a
+// repeatable migration.
 type NewResult struct {
 	UpFile   string
 	DownFile string
 }
 
 // New creates a pair of empty up and down migration files in the migrations
-// directory.
+// directory, or a single repeatable migration file.
 func New(fs afero.Fs, timeGen timegenerator.Generator, args NewArgs) (*NewResult, error) {
 	if !exists(fs, args.MigrationsDir) {
 		return nil, fmt.Errorf("%w: directory %q does not exist",
@@ -31,6 +36,21 @@ func New(fs afero.Fs, timeGen timegenerator.Generator, args NewArgs) (*NewResult
 	}
 
 	migrationsFs := afero.NewBasePathFs(fs, args.MigrationsDir)
+
+	if args.Repeatable {
+		filename := conduitversion.MigrationFilename(
+			conduitversion.Repeatable(),
+			args.Name,
+			conduitversion.MigrationDirectionUp,
+		)
+
+		if err := afero.WriteFile(migrationsFs, filename, nil, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to create repeatable migration: %w", err)
+		}
+
+		return &NewResult{UpFile: filepath.Join(args.MigrationsDir, filename), DownFile: ""}, nil
+	}
+
 	v := conduitversion.NewFromTime(timeGen.Now())
 
 	upFilename := conduitversion.MigrationFilename(v, args.Name, conduitversion.MigrationDirectionUp)
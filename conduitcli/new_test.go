@@ -38,4 +38,19 @@ func TestNew(t *testing.T) {
 		require.NotNil(t, result)
 		testutil.SnapshotFS(t, fs, baseDir)
 	})
+
+	t.Run("should create a single repeatable migration file, when repeatable", func(t *testing.T) {
+		t.Parallel()
+
+		fs, baseDir, migrationsDir := testutil.NewMigrationsDirBuilder(t).Build()
+		result, err := New(fs, timeGen, NewArgs{
+			MigrationsDir: migrationsDir,
+			Name:          "active_users",
+			Repeatable:    true,
+		})
+
+		require.NoError(t, err)
+		require.Empty(t, result.DownFile)
+		testutil.SnapshotFS(t, fs, baseDir)
+	})
 }
@@ -0,0 +1,42 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// RedoArgs configures a [Redo] operation.
+type RedoArgs struct {
+	DatabaseURL  string
+	AllowHazards []conduit.HazardType
+	Steps        int
+}
+
+// Redo connects to the database, rolls back the latest Steps migrations and
+// applies them again. The returned iterator yields the results of both
+// halves as each migration completes.
+func Redo(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args RedoArgs,
+) (iter.Seq2[*conduit.MigrationResult, error], error) {
+	conn, err := pgx.Connect(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	seq, err := migrator.Redo(ctx, conn, &conduit.MigrateOptions{
+		Steps:        args.Steps,
+		AllowHazards: args.AllowHazards,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to redo migrations: %w", err)
+	}
+
+	return seq, nil
+}
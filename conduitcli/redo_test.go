@@ -0,0 +1,59 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestRedo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should roll back and reapply latest migration, when steps is 1", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql":   "CREATE TABLE users (id INT);",
+			"20230601120000_create_users.down.sql": "DROP TABLE users;",
+			"20230602120000_create_posts.up.sql":   "CREATE TABLE posts (id INT);",
+			"20230602120000_create_posts.down.sql": "DROP TABLE posts;",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := Apply(t.Context(), m, ApplyArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Direction:   direction.DirectionUp,
+		})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		seq, err = Redo(t.Context(), m, RedoArgs{DatabaseURL: testutil.ConnString(pool), Steps: 1})
+
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.Equal(t, direction.DirectionDown, results[0].Direction)
+		assert.Equal(t, "20230602120000_create_posts", results[0].Key())
+		assert.Equal(t, direction.DirectionUp, results[1].Direction)
+		assert.Equal(t, "20230602120000_create_posts", results[1].Key())
+		assert.True(t, testutil.TableExists(t, pool, "posts"))
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := Redo(t.Context(), m, RedoArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
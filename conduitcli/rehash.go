@@ -19,7 +19,10 @@ type RehashArgs struct {
 	RootDir        string
 	MigrationsDir  string
 	DatabaseURL    string
+	HistorySchema  string
+	HistoryTable   string
 	ExcludeSchemas []string
+	TemplateVars   map[string]any
 }
 
 // Rehash recomputes the schema hash from existing migrations and persists it
@@ -40,12 +43,14 @@ func Rehash(
 		return fmt.Errorf("failed to parse database URL: %w", err)
 	}
 
-	stmts, err := sqlsplit.Split(migrations.Schema)
+	stmts, err := sqlsplit.Split(migrations.SchemaFor(
+		migrations.NewTable(args.HistorySchema, args.HistoryTable),
+	))
 	if err != nil {
 		return fmt.Errorf("failed to parse conduit internal schema: %w", err)
 	}
 
-	migrationStmts, err := migrationfile.ReadStmtsFromDir(fs, args.MigrationsDir)
+	migrationStmts, err := migrationfile.ReadStmtsFromDir(fs, args.MigrationsDir, args.TemplateVars)
 	if err != nil {
 		return fmt.Errorf("failed to read migrations: %w", err)
 	}
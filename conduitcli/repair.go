@@ -0,0 +1,41 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// RepairArgs configures a [Repair] operation.
+//
+// Migrations lists the keys of the migrations to repair; when empty, every
+// modified migration is repaired.
+type RepairArgs struct {
+	DatabaseURL string
+	Migrations  []string
+}
+
+// Repair connects to the database and records the current content checksum
+// of modified applied migrations. It returns the keys of the repaired
+// migrations.
+func Repair(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args RepairArgs,
+) ([]string, error) {
+	conn, err := pgx.Connect(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	repaired, err := migrator.Repair(ctx, conn, args.Migrations...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair migrations: %w", err)
+	}
+
+	return repaired, nil
+}
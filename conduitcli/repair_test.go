@@ -0,0 +1,108 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestRepair(t *testing.T) {
+	t.Parallel()
+
+	// applyEdited applies create_users and returns a migrator whose
+	// registry has its content edited since.
+	applyEdited := func(t *testing.T, dbURL string) *conduit.Migrator {
+		t.Helper()
+
+		applied := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			})),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		seq, err := Apply(t.Context(), applied, ApplyArgs{DatabaseURL: dbURL, Direction: direction.DirectionUp})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		return conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id BIGINT);",
+			})),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+	}
+
+	t.Run("should repair every modified migration, when no migration is listed", func(t *testing.T) {
+		t.Parallel()
+
+		dbURL := testutil.ConnString(poolFactory.Pool(t))
+		m := applyEdited(t, dbURL)
+
+		repaired, err := Repair(t.Context(), m, RepairArgs{DatabaseURL: dbURL})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_users"}, repaired)
+	})
+
+	t.Run("should repair nothing, when migrations are already repaired", func(t *testing.T) {
+		t.Parallel()
+
+		dbURL := testutil.ConnString(poolFactory.Pool(t))
+		m := applyEdited(t, dbURL)
+
+		_, err := Repair(t.Context(), m, RepairArgs{DatabaseURL: dbURL})
+		require.NoError(t, err)
+
+		repaired, err := Repair(t.Context(), m, RepairArgs{DatabaseURL: dbURL})
+
+		require.NoError(t, err)
+		assert.Empty(t, repaired)
+	})
+
+	t.Run("should repair listed migration, when it is modified", func(t *testing.T) {
+		t.Parallel()
+
+		dbURL := testutil.ConnString(poolFactory.Pool(t))
+		m := applyEdited(t, dbURL)
+
+		repaired, err := Repair(t.Context(), m, RepairArgs{
+			DatabaseURL: dbURL,
+			Migrations:  []string{"20230601120000_create_users"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_users"}, repaired)
+	})
+
+	t.Run("should return error, when listed migration is not applied", func(t *testing.T) {
+		t.Parallel()
+
+		dbURL := testutil.ConnString(poolFactory.Pool(t))
+		m := applyEdited(t, dbURL)
+
+		_, err := Repair(t.Context(), m, RepairArgs{
+			DatabaseURL: dbURL,
+			Migrations:  []string{"20230602120000_create_posts"},
+		})
+
+		require.ErrorIs(t, err, conduit.ErrMigrationNotApplied)
+		require.ErrorContains(t, err, "failed to repair migrations")
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := Repair(t.Context(), m, RepairArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
@@ -0,0 +1,36 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit"
+)
+
+// StatusArgs configures a [Status] operation.
+type StatusArgs struct {
+	DatabaseURL string
+}
+
+// Status connects to the database and reports the state of every migration
+// known to the migrator's registry or recorded in its history table.
+func Status(
+	ctx context.Context,
+	migrator *conduit.Migrator,
+	args StatusArgs,
+) (*conduit.StatusReport, error) {
+	conn, err := pgx.Connect(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	report, err := migrator.Status(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,54 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report applied and pending migrations", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			"20230602120000_create_posts.up.sql": "CREATE TABLE posts (id INT);",
+		})
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := Apply(t.Context(), m, ApplyArgs{
+			DatabaseURL: testutil.ConnString(pool),
+			Direction:   direction.DirectionUp,
+			Steps:       1,
+		})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		report, err := Status(t.Context(), m, StatusArgs{DatabaseURL: testutil.ConnString(pool)})
+
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 1)
+		assert.Len(t, report.Pending(), 1)
+		assert.Empty(t, report.Missing())
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, nil)))
+
+		_, err := Status(t.Context(), m, StatusArgs{DatabaseURL: "invalid://url"})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
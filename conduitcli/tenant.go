@@ -0,0 +1,95 @@
+package conduitcli
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go.inout.gg/conduit"
+)
+
+// ApplyTenantsArgs configures an [ApplyTenants] operation.
+type ApplyTenantsArgs struct {
+	TenantsQuery string
+	ApplyArgs
+}
+
+// ApplyTenants connects to the database, lists the tenant schemas with the
+// tenants query and applies migrations to each of them, see
+// [conduit.TenantRunner.Migrate]. The connections are closed once the
+// returned iterator is done.
+func ApplyTenants(
+	ctx context.Context,
+	runner *conduit.TenantRunner,
+	args ApplyTenantsArgs,
+) (iter.Seq2[*conduit.TenantResult, error], error) {
+	pool, err := pgxpool.New(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	tenants, err := conduit.QueryTenants(ctx, pool, args.TenantsQuery)
+	if err != nil {
+		pool.Close()
+
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	seq, err := runner.Migrate(ctx, args.Direction, pool, tenants, &conduit.MigrateOptions{
+		Steps:        args.Steps,
+		AllowHazards: args.AllowHazards,
+		To:           args.To,
+		ToTime:       args.ToTime,
+	})
+	if err != nil {
+		pool.Close()
+
+		return nil, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return func(yield func(*conduit.TenantResult, error) bool) {
+		defer pool.Close()
+
+		for result, err := range seq {
+			if !yield(result, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// TenantsStatusArgs configures a [TenantsStatus] operation.
+type TenantsStatusArgs struct {
+	DatabaseURL  string
+	TenantsQuery string
+}
+
+// TenantsStatus connects to the database, lists the tenant schemas with the
+// tenants query and reports the state of every migration of each of them.
+func TenantsStatus(
+	ctx context.Context,
+	runner *conduit.TenantRunner,
+	args TenantsStatusArgs,
+) ([]*conduit.TenantStatus, error) {
+	pool, err := pgxpool.New(ctx, args.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	tenants, err := conduit.QueryTenants(ctx, pool, args.TenantsQuery)
+	if err != nil {
+		//nolint:wrapcheck
+		return nil, err
+	}
+
+	statuses, err := runner.Status(ctx, pool, tenants)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	return statuses, nil
+}
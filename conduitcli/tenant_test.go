@@ -0,0 +1,123 @@
+package conduitcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+const tenantsQuery = "SELECT unnest(ARRAY['tenant_a', 'tenant_b'])"
+
+func TestApplyTenants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should migrate every tenant, when tenants query lists them", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		for _, schema := range []string{"tenant_a", "tenant_b"} {
+			testutil.Exec(t, pool, string(migrations.SchemaFor(migrations.NewTable(schema, ""))))
+		}
+
+		r := conduit.NewTenantRunner([]conduit.Option{
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			})),
+		})
+
+		seq, err := ApplyTenants(t.Context(), r, ApplyTenantsArgs{
+			TenantsQuery: tenantsQuery,
+			ApplyArgs: ApplyArgs{
+				DatabaseURL: testutil.ConnString(pool),
+				Direction:   direction.DirectionUp,
+			},
+		})
+
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.ElementsMatch(t, []string{"tenant_a", "tenant_b"}, []string{results[0].Schema, results[1].Schema})
+		assert.False(t, testutil.TableExists(t, pool, "users"))
+	})
+
+	t.Run("should return error, when tenants query is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(testregistry.NewRegistry(t, nil))})
+
+		_, err := ApplyTenants(t.Context(), r, ApplyTenantsArgs{
+			TenantsQuery: "SELECT FROM missing_tenants",
+			ApplyArgs: ApplyArgs{
+				DatabaseURL: testutil.ConnString(pool),
+				Direction:   direction.DirectionUp,
+			},
+		})
+
+		require.ErrorContains(t, err, "failed to query tenants")
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(testregistry.NewRegistry(t, nil))})
+
+		_, err := ApplyTenants(t.Context(), r, ApplyTenantsArgs{
+			TenantsQuery: tenantsQuery,
+			ApplyArgs:    ApplyArgs{DatabaseURL: "invalid://url", Direction: direction.DirectionUp},
+		})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
+
+func TestTenantsStatus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report each tenant, when tenants query lists them", func(t *testing.T) {
+		t.Parallel()
+
+		pool := poolFactory.Pool(t)
+		for _, schema := range []string{"tenant_a", "tenant_b"} {
+			testutil.Exec(t, pool, string(migrations.SchemaFor(migrations.NewTable(schema, ""))))
+		}
+
+		r := conduit.NewTenantRunner([]conduit.Option{
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			})),
+		})
+
+		statuses, err := TenantsStatus(t.Context(), r, TenantsStatusArgs{
+			DatabaseURL:  testutil.ConnString(pool),
+			TenantsQuery: tenantsQuery,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		assert.Equal(t, "tenant_a", statuses[0].Schema)
+		assert.Len(t, statuses[0].Report.Pending(), 1)
+		assert.Equal(t, "tenant_b", statuses[1].Schema)
+		assert.Len(t, statuses[1].Report.Pending(), 1)
+	})
+
+	t.Run("should return error, when database URL is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(testregistry.NewRegistry(t, nil))})
+
+		_, err := TenantsStatus(t.Context(), r, TenantsStatusArgs{
+			DatabaseURL:  "invalid://url",
+			TenantsQuery: tenantsQuery,
+		})
+
+		require.ErrorContains(t, err, "failed to connect to database")
+	})
+}
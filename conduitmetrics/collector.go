@@ -0,0 +1,217 @@
+// Package conduitmetrics exports Prometheus metrics for conduit migration
+// runs.
+//
+// A [Collector] is both a [conduit.Observer], to be passed to the Migrator
+// with [conduit.WithObserver], and a [prometheus.Collector], to be
+// registered with a Prometheus registry:
+//
+//	collector := conduitmetrics.NewCollector()
+//	prometheus.MustRegister(collector)
+//
+//	migrator := conduit.NewMigrator(conduit.WithObserver(collector))
+package conduitmetrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+)
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+
+	reasonDrift = "drift"
+	reasonError = "error"
+)
+
+var (
+	_ conduit.Observer     = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// Option configures a Collector.
+type Option func(*config)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	buckets     []float64
+}
+
+// WithNamespace sets the namespace metric names are prefixed with.
+// Defaults to "conduit".
+func WithNamespace(namespace string) Option {
+	return func(c *config) { c.namespace = namespace }
+}
+
+// WithConstLabels sets labels added to every metric, for example to tell
+// apart the databases of several migrators.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) { c.constLabels = labels }
+}
+
+// WithBuckets sets the buckets of the migration duration and lock wait
+// histograms. Defaults to [prometheus.DefBuckets].
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) { c.buckets = buckets }
+}
+
+// Collector records migration runs as Prometheus metrics:
+//
+//   - conduit_migrations_applied_total{result}: up migrations run.
+//   - conduit_migrations_rolled_back_total{result}: down migrations run.
+//   - conduit_migration_duration_seconds{migration,direction}: time each
+//     migration took.
+//   - conduit_lock_wait_seconds: time spent waiting for the advisory lock.
+//   - conduit_drift_check_failures_total{reason}: failed schema drift
+//     checks, either because drift was found or because of an error.
+//   - conduit_hazards_blocked_total{hazard}: hazards that stopped a
+//     migration from running.
+//   - conduit_pending_migrations: migrations not yet applied.
+type Collector struct {
+	applied           *prometheus.CounterVec
+	rolledBack        *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	lockWait          prometheus.Histogram
+	driftCheckFailure *prometheus.CounterVec
+	hazardsBlocked    *prometheus.CounterVec
+	pending           prometheus.Gauge
+}
+
+// NewCollector returns a Collector with all metrics at zero.
+func NewCollector(opts ...Option) *Collector {
+	//nolint:exhaustruct
+	cfg := &config{namespace: "conduit", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Collector{
+		applied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "migrations_applied_total",
+			Help:        "Number of up migrations run, by result.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"result"}),
+		rolledBack: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "migrations_rolled_back_total",
+			Help:        "Number of down migrations run, by result.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "migration_duration_seconds",
+			Help:        "Time each migration took to run.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{"migration", "direction"}),
+		lockWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "lock_wait_seconds",
+			Help:        "Time spent waiting for the migration advisory lock.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}),
+		driftCheckFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "drift_check_failures_total",
+			Help:        "Number of failed schema drift checks, by reason.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"reason"}),
+		hazardsBlocked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "hazards_blocked_total",
+			Help:        "Number of hazards that stopped a migration from running, by hazard type.",
+			ConstLabels: cfg.constLabels,
+		}, []string{"hazard"}),
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   cfg.namespace,
+			Name:        "pending_migrations",
+			Help:        "Number of migrations not yet applied.",
+			ConstLabels: cfg.constLabels,
+		}),
+	}
+}
+
+// Describe implements [prometheus.Collector].
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.applied.Describe(ch)
+	c.rolledBack.Describe(ch)
+	c.duration.Describe(ch)
+	c.lockWait.Describe(ch)
+	c.driftCheckFailure.Describe(ch)
+	c.hazardsBlocked.Describe(ch)
+	c.pending.Describe(ch)
+}
+
+// Collect implements [prometheus.Collector].
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.applied.Collect(ch)
+	c.rolledBack.Collect(ch)
+	c.duration.Collect(ch)
+	c.lockWait.Collect(ch)
+	c.driftCheckFailure.Collect(ch)
+	c.hazardsBlocked.Collect(ch)
+	c.pending.Collect(ch)
+}
+
+// ObserveLock implements [conduit.Observer]. Failed attempts are recorded
+// too, as they waited all the same.
+func (c *Collector) ObserveLock(wait time.Duration, _ error) {
+	c.lockWait.Observe(wait.Seconds())
+}
+
+// ObserveDriftCheck implements [conduit.Observer].
+func (c *Collector) ObserveDriftCheck(err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, conduit.ErrSchemaDrift):
+		c.driftCheckFailure.WithLabelValues(reasonDrift).Inc()
+	default:
+		c.driftCheckFailure.WithLabelValues(reasonError).Inc()
+	}
+}
+
+// ObserveHazardsBlocked implements [conduit.Observer].
+func (c *Collector) ObserveHazardsBlocked(
+	_ *conduit.Migration,
+	_ conduit.Direction,
+	hazards []conduitregistry.Hazard,
+) {
+	for _, h := range hazards {
+		c.hazardsBlocked.WithLabelValues(string(h.Type)).Inc()
+	}
+}
+
+// ObserveMigration implements [conduit.Observer].
+func (c *Collector) ObserveMigration(
+	migration *conduit.Migration,
+	dir conduit.Direction,
+	d time.Duration,
+	err error,
+) {
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+
+	switch dir {
+	case conduit.DirectionUp:
+		c.applied.WithLabelValues(result).Inc()
+	case conduit.DirectionDown:
+		c.rolledBack.WithLabelValues(result).Inc()
+	}
+
+	c.duration.WithLabelValues(migration.Key(), string(dir)).Observe(d.Seconds())
+}
+
+// ObservePending implements [conduit.Observer].
+func (c *Collector) ObservePending(n int) {
+	c.pending.Set(float64(n))
+}
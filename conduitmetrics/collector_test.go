@@ -0,0 +1,143 @@
+package conduitmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+func newMigration(t *testing.T) *conduit.Migration {
+	t.Helper()
+
+	r := conduitregistry.New()
+	require.NoError(t, r.Register(
+		conduitversion.NewFromTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)),
+		"create_users",
+		func(context.Context, *pgx.Conn) error { return nil },
+		nil,
+	))
+
+	for _, m := range r.Migrations() {
+		return m
+	}
+
+	t.Fatal("registry has no migrations")
+
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should count migrations by direction and result", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		c := NewCollector()
+		migration := newMigration(t)
+
+		// Act
+		c.ObserveMigration(migration, conduit.DirectionUp, time.Second, nil)
+		c.ObserveMigration(migration, conduit.DirectionUp, time.Second, errors.New("boom"))
+		c.ObserveMigration(migration, conduit.DirectionDown, time.Second, nil)
+
+		// Assert
+		assert.InDelta(t, 1, testutil.ToFloat64(c.applied.WithLabelValues(resultSuccess)), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(c.applied.WithLabelValues(resultFailure)), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(c.rolledBack.WithLabelValues(resultSuccess)), 0)
+		assert.Equal(t, 2, testutil.CollectAndCount(c.duration))
+	})
+
+	t.Run("should count drift check failures by reason", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		c := NewCollector()
+
+		// Act
+		c.ObserveDriftCheck(nil)
+		c.ObserveDriftCheck(fmt.Errorf("check: %w", conduit.ErrSchemaDrift))
+		c.ObserveDriftCheck(errors.New("connection reset"))
+
+		// Assert
+		assert.InDelta(t, 1, testutil.ToFloat64(c.driftCheckFailure.WithLabelValues(reasonDrift)), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(c.driftCheckFailure.WithLabelValues(reasonError)), 0)
+	})
+
+	t.Run("should count blocked hazards by type", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		c := NewCollector()
+
+		// Act
+		c.ObserveHazardsBlocked(newMigration(t), conduit.DirectionUp, []conduitregistry.Hazard{
+			{Type: conduit.HazardTypeDeletesData, Message: "drops users"},
+			{Type: conduit.HazardTypeDeletesData, Message: "drops posts"},
+			{Type: conduit.HazardTypeIndexBuild, Message: "builds index"},
+		})
+
+		// Assert
+		assert.InDelta(t, 2, testutil.ToFloat64(
+			c.hazardsBlocked.WithLabelValues(string(conduit.HazardTypeDeletesData)),
+		), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(
+			c.hazardsBlocked.WithLabelValues(string(conduit.HazardTypeIndexBuild)),
+		), 0)
+	})
+
+	t.Run("should report the latest pending count", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		c := NewCollector()
+
+		// Act
+		c.ObservePending(3)
+		c.ObservePending(1)
+
+		// Assert
+		assert.InDelta(t, 1, testutil.ToFloat64(c.pending), 0)
+	})
+
+	t.Run("should register every metric, when namespace and labels are set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		c := NewCollector(
+			WithNamespace("app"),
+			WithConstLabels(prometheus.Labels{"database": "main"}),
+		)
+		reg := prometheus.NewPedanticRegistry()
+
+		// Act
+		err := reg.Register(c)
+		c.ObserveLock(time.Millisecond, nil)
+
+		// Assert
+		require.NoError(t, err)
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(families))
+		for _, f := range families {
+			names = append(names, f.GetName())
+		}
+
+		assert.Contains(t, names, "app_lock_wait_seconds")
+		assert.Contains(t, names, "app_pending_migrations")
+	})
+}
@@ -0,0 +1,38 @@
+package conduitregistry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"go.inout.gg/conduit/internal/direction"
+)
+
+// Checksum returns the hex-encoded SHA-256 of the migration's normalized SQL
+// content for the given direction. Normalization keeps query statements
+// only and drops blank lines and trailing whitespace, so that reformatting
+// a file does not change its checksum.
+//
+// Go migrations have no content and return an empty checksum.
+func (m *Migration) Checksum(dir direction.Direction) string {
+	return checksum(m.Content(dir))
+}
+
+func checksum(content string) string {
+	lines := strings.Split(content, "\n")
+
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(normalized, "\n")))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package conduitregistry
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+// DependsOnDirectivePrefix marks a migration the migration must run after.
+// Format: ---- depends-on: <namespace>/<version>_<name> ----, or
+// ---- depends-on: <version>_<name> ---- for the default namespace.
+const DependsOnDirectivePrefix = "---- depends-on:"
+
+var (
+	ErrDependencyCycle   = errors.New("migration dependency cycle")
+	ErrUnknownDependency = errors.New("migration depends on unknown migration")
+)
+
+// DependsOn returns the keys of the migrations that must run before the
+// migration, as declared with [DependsOnDirectivePrefix] in its up file.
+func (m *Migration) DependsOn() []string { return m.up.dependsOn }
+
+// Sorted returns the migrations of r in the order they are applied: each
+// migration after the migrations it depends on, and otherwise by version,
+// namespace and name. Migrations are rolled back in the reverse order.
+//
+// Returns [ErrUnknownDependency] if a migration depends on a migration that
+// is not in r, and [ErrDependencyCycle] if migrations depend on each other.
+func (r *Registry) Sorted() ([]*Migration, error) {
+	return sortMigrations(r.migrations, true)
+}
+
+// Compare orders migrations by version, namespace and name.
+func Compare(a, b *Migration) int {
+	if c := a.Version().Compare(b.Version()); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(a.Namespace(), b.Namespace()); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.Name(), b.Name())
+}
+
+// sortMigrations orders migrations topologically by their dependencies,
+// picking the lowest migration by [Compare] among those ready to run.
+//
+// Unless strict, dependencies on migrations of other namespaces that are not
+// in migrations are ignored, as they may be added by [Compose].
+func sortMigrations(migrations map[string]*Migration, strict bool) ([]*Migration, error) {
+	remaining := make(map[string]int, len(migrations)) // key -> unmet dependencies
+	dependents := make(map[string][]*Migration, len(migrations))
+
+	for key, m := range migrations {
+		remaining[key] = 0
+
+		for _, dep := range m.DependsOn() {
+			if _, ok := migrations[dep]; !ok {
+				if !strict && keyNamespace(dep) != m.Namespace() {
+					continue
+				}
+
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, key, dep)
+			}
+
+			dependents[dep] = append(dependents[dep], m)
+			remaining[key]++
+		}
+	}
+
+	var ready []*Migration
+
+	for key, n := range remaining {
+		if n == 0 {
+			ready = append(ready, migrations[key])
+		}
+	}
+
+	sorted := make([]*Migration, 0, len(migrations))
+
+	for len(ready) > 0 {
+		slices.SortFunc(ready, Compare)
+
+		next := ready[0]
+		ready = ready[1:]
+
+		sorted = append(sorted, next)
+		delete(remaining, next.Key())
+
+		for _, m := range dependents[next.Key()] {
+			remaining[m.Key()]--
+			if remaining[m.Key()] == 0 {
+				ready = append(ready, m)
+			}
+		}
+	}
+
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(findCycle(migrations, remaining), " -> "))
+	}
+
+	return sorted, nil
+}
+
+// findCycle returns the keys of a dependency cycle among the migrations
+// left unsorted, each of which has a dependency that is left unsorted too.
+func findCycle(migrations map[string]*Migration, remaining map[string]int) []string {
+	key := slices.Min(slices.Collect(maps.Keys(remaining)))
+	seen := make(map[string]int)
+
+	var path []string
+
+	for {
+		if i, ok := seen[key]; ok {
+			return append(path[i:], key)
+		}
+
+		seen[key] = len(path)
+		path = append(path, key)
+
+		deps := slices.Sorted(slices.Values(migrations[key].DependsOn()))
+		key = deps[slices.IndexFunc(deps, func(dep string) bool {
+			_, ok := remaining[dep]
+			return ok
+		})]
+	}
+}
+
+// keyNamespace returns the namespace part of a migration key.
+func keyNamespace(key string) string {
+	namespace, _, ok := strings.Cut(key, "/")
+	if !ok {
+		return ""
+	}
+
+	return namespace
+}
+
+// parseDependency parses the key of a depends-on directive.
+func parseDependency(key string) (string, error) {
+	namespace, rest, ok := strings.Cut(key, "/")
+	if !ok {
+		namespace, rest = "", key
+	}
+
+	version, name, ok := strings.Cut(rest, "_")
+	if !ok || name == "" {
+		return "", fmt.Errorf(
+			"malformed depends-on directive, expected: [<namespace>/]<version>_<name>, got: %q",
+			key,
+		)
+	}
+
+	v, err := conduitversion.Parse(version)
+	if err != nil {
+		return "", fmt.Errorf("malformed depends-on directive %q: %w", key, err)
+	}
+
+	return Key(namespace, v.String(), name), nil
+}
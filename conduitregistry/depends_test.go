@@ -0,0 +1,189 @@
+package conduitregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func keys(migrations []*Migration) []string {
+	return sliceutil.Map(migrations, func(m *Migration) string { return m.Key() })
+}
+
+func TestRegistry_Sorted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should order by version, when no dependencies are declared", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230602120000_b.up.sql", "SELECT 2;").
+			WithFile("20230601120000_a.up.sql", "SELECT 1;").
+			Build()
+		r := FromFS(fs, dir)
+
+		// Act
+		sorted, err := r.Sorted()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_a", "20230602120000_b"}, keys(sorted))
+	})
+
+	t.Run("should order migration after its dependency, when dependency is newer", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		appFs, _, appDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile(
+				"20230601120000_add_posts_fk.up.sql",
+				"---- depends-on: shield/20230605120000_create_users ----\nSELECT 1;",
+			).
+			WithFile("20230610120000_create_tags.up.sql", "SELECT 2;").
+			Build()
+		libFs, _, libDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230605120000_create_users.up.sql", "SELECT 3;").
+			Build()
+
+		r, err := Compose(FromFS(appFs, appDir), FromFS(libFs, libDir, WithNamespace("shield")))
+		require.NoError(t, err)
+
+		// Act
+		sorted, err := r.Sorted()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"shield/20230605120000_create_users",
+			"20230601120000_add_posts_fk",
+			"20230610120000_create_tags",
+		}, keys(sorted))
+		assert.Equal(t, []string{"shield/20230605120000_create_users"}, sorted[1].DependsOn())
+	})
+
+	t.Run("should return error, when migrations depend on each other", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+		for _, m := range []*Migration{
+			newDependentMigration(t, "20230601120000_a", "20230602120000_b"),
+			newDependentMigration(t, "20230602120000_b", "20230601120000_a"),
+		} {
+			r.migrations[m.Key()] = m
+		}
+
+		// Act
+		_, err := r.Sorted()
+
+		// Assert
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		assert.ErrorContains(t, err, "20230601120000_a -> 20230602120000_b -> 20230601120000_a")
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should panic, when dependency in the same namespace is missing", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- depends-on: 20230501120000_gone ----\nSELECT 1;").
+			Build()
+
+		// Act & Assert
+		assert.Panics(t, func() { FromFS(fs, dir) })
+	})
+
+	t.Run("should defer check to Compose, when dependency is in another namespace", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- depends-on: shield/20230501120000_users ----\nSELECT 1;").
+			Build()
+		r := FromFS(fs, dir)
+
+		// Act
+		_, err := Compose(r)
+
+		// Assert
+		require.ErrorIs(t, err, ErrUnknownDependency)
+	})
+
+	t.Run("should defer check to Sorted, when registries are merged", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- depends-on: shield/20230501120000_users ----\nSELECT 1;").
+			Build()
+
+		// Act
+		r, err := Merge(FromFS(fs, dir))
+		require.NoError(t, err)
+		_, err = r.Sorted()
+
+		// Assert
+		require.ErrorIs(t, err, ErrUnknownDependency)
+	})
+
+	t.Run("should return error, when directive is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- depends-on: users ----\nSELECT 1;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorContains(t, err, "malformed depends-on directive")
+	})
+
+	t.Run("should return error, when directive is in a down file", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "SELECT 1;").
+			WithFile("20230601120000_a.down.sql", "---- depends-on: 20230501120000_b ----\nSELECT 1;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorContains(t, err, "depends-on directives belong in the up migration")
+	})
+}
+
+// newDependentMigration returns a SQL migration with key that depends on
+// the migrations with deps keys.
+func newDependentMigration(t *testing.T, key string, deps ...string) *Migration {
+	t.Helper()
+
+	content := ""
+	for _, dep := range deps {
+		content += "---- depends-on: " + dep + " ----\n"
+	}
+
+	fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+		WithFile(key+".up.sql", content+"SELECT 1;").
+		Build()
+
+	migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
+	require.NoError(t, err)
+	require.Len(t, migrations, 1)
+
+	return migrations[0]
+}
@@ -0,0 +1,24 @@
+package conduitregistry
+
+import "context"
+
+// StatementHooks are called around each statement of a SQL migration
+// applied with a context returned by [WithStatementHooks]. Go migrations
+// have no statements, so the hooks are never called for them.
+//
+// Before is called with the statement about to run; an error skips the
+// statement and fails the migration. After is called with the statement
+// and the error it failed with, if any; an error fails the migration.
+// Either may be nil.
+type StatementHooks struct {
+	Before func(ctx context.Context, stmt string) error
+	After  func(ctx context.Context, stmt string, err error) error
+}
+
+type statementHooksKey struct{}
+
+// WithStatementHooks returns ctx carrying hooks, which migrations applied
+// with the returned context call around each statement.
+func WithStatementHooks(ctx context.Context, hooks StatementHooks) context.Context {
+	return context.WithValue(ctx, statementHooksKey{}, hooks)
+}
@@ -8,15 +8,21 @@ import (
 
 	"go.inout.gg/conduit/internal/direction"
 	"go.inout.gg/conduit/pkg/conduitversion"
+	"go.inout.gg/conduit/pkg/sqlsplit"
 )
 
 //nolint:gochecknoglobals
 var emptyMigrateFunc = &migrateFunc{
-	fn:      func(_ context.Context, _ *pgx.Conn) error { return nil },
-	fnx:     func(_ context.Context, _ pgx.Tx) error { return nil },
-	hazards: nil,
-	content: "",
-	useTx:   false,
+	fn:        func(_ context.Context, _ *pgx.Conn) error { return nil },
+	fnx:       func(_ context.Context, _ pgx.Tx) error { return nil },
+	hazards:   nil,
+	dependsOn: nil,
+	settings:  nil,
+	retry:     nil,
+	tags:      nil,
+	stmts:     nil,
+	content:   "",
+	useTx:     false,
 }
 
 // Hazard represents a hazardous operation detected in a migration.
@@ -26,20 +32,26 @@ type Hazard struct {
 }
 
 type migrateFunc struct {
-	fn      applyFunc
-	fnx     applyFuncTx
-	content string
-	hazards []Hazard
-	useTx   bool
+	fn        ApplyFunc
+	fnx       ApplyFuncTx
+	content   string
+	hazards   []Hazard
+	dependsOn []string // keys of the migrations to run first
+	settings  []Setting
+	retry     *RetryPolicy // nil unless declared
+	tags      []string
+	stmts     []sqlsplit.Stmt // query statements of a SQL migration
+	useTx     bool
 }
 
-// Migration represents a single versioned database migration with up and down
-// SQL content.
+// Migration represents a single versioned database migration, backed either
+// by SQL content or by Go functions.
 type Migration struct {
-	up      *migrateFunc
-	down    *migrateFunc
-	version conduitversion.Version
-	name    string
+	up        *migrateFunc
+	down      *migrateFunc
+	version   conduitversion.Version
+	namespace string
+	name      string
 }
 
 // UseTx reports whether the migration should run inside a transaction
@@ -59,6 +71,14 @@ func (m *Migration) Version() conduitversion.Version { return m.version }
 
 func (m *Migration) Name() string { return m.name }
 
+// Namespace returns the namespace of the registry the migration was
+// added to. The default namespace is empty.
+func (m *Migration) Namespace() string { return m.namespace }
+
+// Key returns the identifier of the migration, unique within a registry.
+// See [Key] for the format.
+func (m *Migration) Key() string { return Key(m.namespace, m.version.String(), m.name) }
+
 // Content returns the raw SQL content for the given direction.
 func (m *Migration) Content(dir direction.Direction) string {
 	switch dir {
@@ -98,6 +118,46 @@ func (m *Migration) Apply(ctx context.Context, dir direction.Direction, conn *pg
 	return direction.ErrUnknownDirection
 }
 
+// ApplyFrom executes a migration that does not run in a transaction on a
+// bare connection, skipping its first from statements. After each statement
+// succeeds, done is called with the number of statements executed so far,
+// counting the skipped ones; an error from done stops the migration.
+//
+// Only SQL migrations can be resumed part-way: for Go migrations, from must
+// be zero, or [ErrNotResumable] is returned, and done is never called.
+func (m *Migration) ApplyFrom(
+	ctx context.Context,
+	dir direction.Direction,
+	conn *pgx.Conn,
+	from int,
+	done func(int) error,
+) error {
+	debug.Assert(conn != nil, "expected conn to be defined")
+
+	var f *migrateFunc
+
+	switch dir {
+	case direction.DirectionUp:
+		f = m.up
+	case direction.DirectionDown:
+		f = m.down
+	default:
+		return direction.ErrUnknownDirection
+	}
+
+	debug.Assert(!f.useTx, "expected a migration that does not run in a transaction")
+
+	if f.stmts == nil {
+		if from > 0 {
+			return ErrNotResumable
+		}
+
+		return f.fn(ctx, conn)
+	}
+
+	return execStmts(ctx, conn, f.stmts, from, done)
+}
+
 // ApplyTx executes the migration within the provided transaction.
 func (m *Migration) ApplyTx(ctx context.Context, dir direction.Direction, tx pgx.Tx) error {
 	debug.Assert(tx != nil, "expected tx to be defined")
@@ -112,8 +172,6 @@ func (m *Migration) ApplyTx(ctx context.Context, dir direction.Direction, tx pgx
 	return direction.ErrUnknownDirection
 }
 
-func (m *Migration) migrationKey() string { return migrationKey(m.version, m.name) }
-
 func (m *Migration) migrateDown(ctx context.Context, conn *pgx.Conn, tx pgx.Tx) error {
 	if m.down.useTx {
 		debug.Assert(conn == nil && tx != nil, "expected only tx to be defined")
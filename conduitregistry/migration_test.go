@@ -32,7 +32,7 @@ func TestMigration_Apply(t *testing.T) {
 				"DROP TABLE test_apply_up;").
 			Build()
 
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 		require.NoError(t, err)
 		require.Len(t, migrations, 1)
 
@@ -68,7 +68,7 @@ func TestMigration_Apply(t *testing.T) {
 			WithFile("20230601120000_bad.up.sql", "INVALID SQL STATEMENT;").
 			Build()
 
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 		require.NoError(t, err)
 		require.Len(t, migrations, 1)
 
@@ -102,7 +102,7 @@ func TestMigration_ApplyTx(t *testing.T) {
 				"---- enable-tx ----\nDROP TABLE test_apply_tx;").
 			Build()
 
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 		require.NoError(t, err)
 		require.Len(t, migrations, 1)
 
@@ -136,7 +136,7 @@ func TestMigration_ApplyTx(t *testing.T) {
 			WithFile("20230601120000_bad_tx.up.sql", "---- enable-tx ----\nINVALID SQL;").
 			Build()
 
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 		require.NoError(t, err)
 		require.Len(t, migrations, 1)
 
@@ -154,3 +154,48 @@ func TestMigration_ApplyTx(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestMigration_Checksum(t *testing.T) {
+	t.Parallel()
+
+	parse := func(t *testing.T, content string) *Migration {
+		t.Helper()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_create_users.up.sql", content).
+			Build()
+
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+
+		return migrations[0]
+	}
+
+	t.Run("should ignore comments and whitespace, when computing checksum", func(t *testing.T) {
+		t.Parallel()
+
+		a := parse(t, "CREATE TABLE users (id INT);")
+		b := parse(t, "-- users table\n\nCREATE TABLE users (id INT);  \r\n\n")
+
+		assert.Len(t, a.Checksum(direction.DirectionUp), 64)
+		assert.Equal(t, a.Checksum(direction.DirectionUp), b.Checksum(direction.DirectionUp))
+	})
+
+	t.Run("should change checksum, when statements change", func(t *testing.T) {
+		t.Parallel()
+
+		a := parse(t, "CREATE TABLE users (id INT);")
+		b := parse(t, "CREATE TABLE users (id BIGINT);")
+
+		assert.NotEqual(t, a.Checksum(direction.DirectionUp), b.Checksum(direction.DirectionUp))
+	})
+
+	t.Run("should return empty checksum, when migration has no content", func(t *testing.T) {
+		t.Parallel()
+
+		a := parse(t, "CREATE TABLE users (id INT);")
+
+		assert.Empty(t, a.Checksum(direction.DirectionDown))
+	})
+}
@@ -3,57 +3,287 @@ package conduitregistry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"maps"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/spf13/afero"
 	"go.inout.gg/foundations/must"
+
+	"go.inout.gg/conduit/pkg/conduitversion"
 )
 
 var (
-	ErrEmptyMigration = errors.New("migration is empty")
-	ErrUpExists       = errors.New("up migration already registered")
-	ErrDownExists     = errors.New("down migration already registered")
+	ErrEmptyMigration   = errors.New("migration is empty")
+	ErrUpExists         = errors.New("up migration already registered")
+	ErrDownExists       = errors.New("down migration already registered")
+	ErrNotResumable     = errors.New("migration cannot be resumed part-way")
+	ErrRepeatableGo     = errors.New("repeatable migrations must be SQL files")
+	ErrInvalidNamespace = errors.New("invalid namespace")
 )
 
 type (
-	applyFunc   func(context.Context, *pgx.Conn) error
-	applyFuncTx func(context.Context, pgx.Tx) error
+	// ApplyFunc applies a Go migration on a bare connection outside a
+	// transaction.
+	ApplyFunc func(context.Context, *pgx.Conn) error
+
+	// ApplyFuncTx applies a Go migration within a transaction.
+	ApplyFuncTx func(context.Context, pgx.Tx) error
 )
 
-// Registry holds a set of parsed SQL migrations keyed by version and name.
+// Registry holds a set of SQL and Go migrations keyed by namespace, version
+// and name.
 type Registry struct {
-	migrations map[string]*Migration
+	migrations   map[string]*Migration
+	templateVars map[string]any
+	namespace    string
+	err          error // invalid option, returned by Load and the Register methods
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithNamespace tags every migration added to the registry with ns, e.g.
+// "shield" or "app". Migrations from different namespaces never collide,
+// even when they share a version and name.
+//
+// The namespace must not contain a "/", which separates it from the
+// version in migration keys; otherwise [Load] and the Register methods
+// return [ErrInvalidNamespace]. When omitted, migrations belong to the
+// default (empty) namespace.
+func WithNamespace(ns string) Option {
+	return func(r *Registry) {
+		r.namespace = ns
+
+		if strings.Contains(ns, "/") {
+			r.err = fmt.Errorf("%w: %q contains a \"/\"", ErrInvalidNamespace, ns)
+		}
+	}
 }
 
-// New returns an empty Registry.
-func New() *Registry {
-	return &Registry{
-		migrations: make(map[string]*Migration),
+// New returns an empty Registry. An invalid option, such as a namespace
+// rejected by [WithNamespace], is returned by the Register methods.
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		migrations:   make(map[string]*Migration),
+		templateVars: nil,
+		namespace:    "",
+		err:          nil,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	return r
 }
 
-// FromIOFS parses all .up.sql and .down.sql files under root in the given fs (io/fs)
-// and returns a populated [Registry]. It panics if parsing fails.
-func FromIOFS(fs fs.FS, root string) *Registry {
-	return FromFS(afero.FromIOFS{FS: fs}, root)
+// FromIOFS parses all .up.sql, .down.sql and repeatable R_<name>.sql files
+// under root in the given fs (io/fs) and returns a populated [Registry]. It
+// panics if parsing fails.
+func FromIOFS(fs fs.FS, root string, opts ...Option) *Registry {
+	return FromFS(afero.FromIOFS{FS: fs}, root, opts...)
 }
 
-// FromFS parses all .up.sql and .down.sql files under root in the given fs (afero.Fs)
-// and returns a populated [Registry]. It panics if parsing fails.
-func FromFS(fs afero.Fs, root string) *Registry {
-	r := New()
+// FromFS parses all .up.sql, .down.sql and repeatable R_<name>.sql files
+// under root in the given fs (afero.Fs) and returns a populated [Registry].
+// Files with [TemplateDirective] are rendered first, see [WithTemplateVars].
+// It panics if parsing or rendering fails, or if the migrations depend on each other in
+// a cycle or on missing migrations of the registry's namespace; see
+// [Registry.Sorted].
+func FromFS(fs afero.Fs, root string, opts ...Option) *Registry {
+	return must.Must(Load(fs, root, opts...))
+}
+
+// Load is [FromFS] for migrations read at run time, such as by a CLI: it
+// returns the error FromFS panics with.
+func Load(fs afero.Fs, root string, opts ...Option) (*Registry, error) {
+	r := New(opts...)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	migrations, err := parseSQLMigrationsFromFS(fs, root, r.templateVars)
+	if err != nil {
+		return nil, err
+	}
 
-	migrations := must.Must(parseSQLMigrationsFromFS(fs, root))
 	for _, m := range migrations {
-		r.migrations[m.migrationKey()] = m
+		m.namespace = r.namespace
+		r.migrations[m.Key()] = m
 	}
 
-	return r
+	if _, err := sortMigrations(r.migrations, false); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Compose combines several registries into a new one so that a single
+// migrator can apply them in one run. Each migration keeps the namespace of
+// the registry it came from.
+//
+// Returns [ErrUpExists] if two registries contain a migration with the same
+// namespace, version and name, and [ErrUnknownDependency] or
+// [ErrDependencyCycle] if the dependencies between the migrations of the
+// combined registry cannot be resolved, see [Registry.Sorted].
+func Compose(registries ...*Registry) (*Registry, error) {
+	r, err := Merge(registries...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Sorted(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Merge combines several registries into a new one like [Compose], but
+// leaves resolving the dependencies between their migrations to
+// [Registry.Sorted], so that a registry built piece by piece, such as the
+// global registry, can depend on migrations of a piece added later.
+//
+// Returns [ErrUpExists] if two registries contain a migration with the same
+// namespace, version and name, and [ErrInvalidNamespace] if a registry was
+// created with an invalid namespace.
+func Merge(registries ...*Registry) (*Registry, error) {
+	r := New()
+
+	for _, other := range registries {
+		if other.err != nil {
+			return nil, other.err
+		}
+
+		for _, m := range other.migrations {
+			if err := r.add(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return r, nil
 }
 
+// Namespace returns the namespace assigned to migrations added to r.
+func (r *Registry) Namespace() string { return r.namespace }
+
 // Migrations returns a shallow copy of the registered migrations map.
 func (r *Registry) Migrations() map[string]*Migration {
 	return maps.Clone(r.migrations)
 }
+
+// Register adds a Go migration that runs outside a transaction. The down
+// function may be nil, in which case rolling back is a no-op.
+//
+// Returns [ErrUpExists] if a migration with the same version and name is
+// already registered in the registry's namespace, and [ErrRepeatableGo] if
+// version is [conduitversion.Repeatable]: Go migrations have no content to
+// tell when to reapply them.
+func (r *Registry) Register(
+	version conduitversion.Version,
+	name string,
+	up, down ApplyFunc,
+) error {
+	if up == nil {
+		return fmt.Errorf("up function for %s: %w", Key(r.namespace, version.String(), name), ErrEmptyMigration)
+	}
+
+	if version.IsRepeatable() {
+		return fmt.Errorf("%s: %w", Key(r.namespace, version.String(), name), ErrRepeatableGo)
+	}
+
+	m := &Migration{
+		namespace: r.namespace,
+		version:   version,
+		name:      name,
+		up:        goMigrateFunc(up),
+		down:      emptyMigrateFunc,
+	}
+	if down != nil {
+		m.down = goMigrateFunc(down)
+	}
+
+	return r.add(m)
+}
+
+// RegisterTx adds a Go migration that runs inside a transaction. The down
+// function may be nil, in which case rolling back is a no-op.
+//
+// Returns [ErrUpExists] if a migration with the same version and name is
+// already registered in the registry's namespace, and [ErrRepeatableGo] if
+// version is [conduitversion.Repeatable].
+func (r *Registry) RegisterTx(
+	version conduitversion.Version,
+	name string,
+	up, down ApplyFuncTx,
+) error {
+	if up == nil {
+		return fmt.Errorf("up function for %s: %w", Key(r.namespace, version.String(), name), ErrEmptyMigration)
+	}
+
+	if version.IsRepeatable() {
+		return fmt.Errorf("%s: %w", Key(r.namespace, version.String(), name), ErrRepeatableGo)
+	}
+
+	m := &Migration{
+		namespace: r.namespace,
+		version:   version,
+		name:      name,
+		up:        goMigrateFuncTx(up),
+		down:      emptyMigrateFunc,
+	}
+	if down != nil {
+		m.down = goMigrateFuncTx(down)
+	}
+
+	return r.add(m)
+}
+
+func (r *Registry) add(m *Migration) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	key := m.Key()
+	if _, ok := r.migrations[key]; ok {
+		return fmt.Errorf("duplicate migration %s: %w", key, ErrUpExists)
+	}
+
+	r.migrations[key] = m
+
+	return nil
+}
+
+func goMigrateFunc(fn ApplyFunc) *migrateFunc {
+	return &migrateFunc{
+		fn:        fn,
+		fnx:       nil,
+		hazards:   nil,
+		dependsOn: nil,
+		settings:  nil,
+		retry:     nil,
+		tags:      nil,
+		stmts:     nil,
+		content:   "",
+		useTx:     false,
+	}
+}
+
+func goMigrateFuncTx(fn ApplyFuncTx) *migrateFunc {
+	return &migrateFunc{
+		fn:        nil,
+		fnx:       fn,
+		hazards:   nil,
+		dependsOn: nil,
+		settings:  nil,
+		retry:     nil,
+		tags:      nil,
+		stmts:     nil,
+		content:   "",
+		useTx:     true,
+	}
+}
@@ -0,0 +1,261 @@
+package conduitregistry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	t.Parallel()
+
+	v := conduitversion.NewFromTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	noop := func(context.Context, *pgx.Conn) error { return nil }
+	noopTx := func(context.Context, pgx.Tx) error { return nil }
+
+	t.Run("should register non-tx migration, when up and down are provided", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+
+		// Act
+		err := r.Register(v, "backfill_users", noop, noop)
+
+		// Assert
+		require.NoError(t, err)
+
+		m, ok := r.Migrations()["20230601120000_backfill_users"]
+		require.True(t, ok)
+		assert.Equal(t, "backfill_users", m.Name())
+
+		upTx, err := m.UseTx(direction.DirectionUp)
+		require.NoError(t, err)
+		assert.False(t, upTx)
+		assert.NotEqual(t, emptyMigrateFunc, m.down)
+	})
+
+	t.Run("should register tx migration, when RegisterTx is used", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+
+		// Act
+		err := r.RegisterTx(v, "backfill_users", noopTx, noopTx)
+
+		// Assert
+		require.NoError(t, err)
+
+		m := r.Migrations()["20230601120000_backfill_users"]
+
+		upTx, err := m.UseTx(direction.DirectionUp)
+		require.NoError(t, err)
+		assert.True(t, upTx)
+
+		downTx, err := m.UseTx(direction.DirectionDown)
+		require.NoError(t, err)
+		assert.True(t, downTx)
+	})
+
+	t.Run("should use empty down migration, when down is nil", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+
+		// Act
+		err := r.Register(v, "backfill_users", noop, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, emptyMigrateFunc, r.Migrations()["20230601120000_backfill_users"].down)
+	})
+
+	t.Run("should return error, when up is nil", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+
+		// Act
+		err := r.Register(v, "backfill_users", nil, noop)
+
+		// Assert
+		require.ErrorIs(t, err, ErrEmptyMigration)
+	})
+
+	t.Run("should return error, when migration is already registered", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+		require.NoError(t, r.Register(v, "backfill_users", noop, nil))
+
+		// Act
+		err := r.RegisterTx(v, "backfill_users", noopTx, nil)
+
+		// Assert
+		require.ErrorIs(t, err, ErrUpExists)
+	})
+
+	t.Run("should return error, when version is repeatable", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New()
+
+		// Act
+		err := r.Register(conduitversion.Repeatable(), "refresh_views", noop, nil)
+		errTx := r.RegisterTx(conduitversion.Repeatable(), "refresh_views", noopTx, nil)
+
+		// Assert
+		require.ErrorIs(t, err, ErrRepeatableGo)
+		require.ErrorIs(t, errTx, ErrRepeatableGo)
+	})
+}
+
+func TestFromFS_Repeatable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should register repeatable migration without down, when file has R prefix", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_create_users.up.sql", "CREATE TABLE users (id INT);").
+			WithFile("R_active_users.sql", "CREATE OR REPLACE VIEW active_users AS SELECT id FROM users;").
+			Build()
+
+		// Act
+		r := FromFS(fs, dir)
+
+		// Assert
+		m, ok := r.Migrations()["R_active_users"]
+		require.True(t, ok)
+		assert.True(t, m.Version().IsRepeatable())
+		assert.Equal(t, "active_users", m.Name())
+		assert.NotEmpty(t, m.Checksum(direction.DirectionUp))
+		assert.Equal(t, emptyMigrateFunc, m.down)
+	})
+}
+
+func TestWithNamespace(t *testing.T) {
+	t.Parallel()
+
+	v := conduitversion.NewFromTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	noop := func(context.Context, *pgx.Conn) error { return nil }
+
+	t.Run("should return error from Load, when namespace contains a slash", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_create_users.up.sql", "CREATE TABLE users (id INT);").
+			Build()
+
+		// Act
+		r, err := Load(fs, dir, WithNamespace("shield/v2"))
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidNamespace)
+		require.EqualError(t, err, `invalid namespace: "shield/v2" contains a "/"`)
+		assert.Nil(t, r)
+	})
+
+	t.Run("should return error from Register, when namespace contains a slash", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := New(WithNamespace("shield/v2"))
+
+		// Act
+		err := r.Register(v, "create_users", noop, nil)
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidNamespace)
+		assert.Empty(t, r.Migrations())
+	})
+
+	t.Run("should return error from Compose, when namespace contains a slash", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		_, err := Compose(New(WithNamespace("shield/v2")), New())
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidNamespace)
+	})
+}
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+
+	v := conduitversion.NewFromTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	noop := func(context.Context, *pgx.Conn) error { return nil }
+
+	t.Run("should keep migrations apart, when namespaces differ", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		shield := New(WithNamespace("shield"))
+		require.NoError(t, shield.Register(v, "create_users", noop, nil))
+
+		app := New(WithNamespace("app"))
+		require.NoError(t, app.Register(v, "create_users", noop, nil))
+
+		// Act
+		r, err := Compose(shield, app)
+
+		// Assert
+		require.NoError(t, err)
+
+		migrations := r.Migrations()
+		require.Len(t, migrations, 2)
+		assert.Equal(t, "shield", migrations["shield/20230601120000_create_users"].Namespace())
+		assert.Equal(t, "app", migrations["app/20230601120000_create_users"].Namespace())
+	})
+
+	t.Run("should tag SQL migrations with namespace, when parsed from fs", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_create_users.up.sql", "CREATE TABLE users (id INT);").
+			Build()
+
+		// Act
+		r := FromFS(fs, dir, WithNamespace("shield"))
+
+		// Assert
+		m, ok := r.Migrations()["shield/20230601120000_create_users"]
+		require.True(t, ok)
+		assert.Equal(t, "shield", m.Namespace())
+		assert.Equal(t, "shield/20230601120000_create_users", m.Key())
+	})
+
+	t.Run("should return error, when same namespace has duplicate migrations", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		a := New()
+		require.NoError(t, a.Register(v, "create_users", noop, nil))
+
+		b := New()
+		require.NoError(t, b.Register(v, "create_users", noop, nil))
+
+		// Act
+		_, err := Compose(a, b)
+
+		// Assert
+		require.ErrorIs(t, err, ErrUpExists)
+	})
+}
@@ -0,0 +1,106 @@
+package conduitregistry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.inout.gg/conduit/internal/direction"
+)
+
+// RetryDirectivePrefix sets the [RetryPolicy] of a migration.
+// Format: ---- retry: <retries> [backoff=<duration>] ----, e.g.
+// ---- retry: 5 backoff=2s ----.
+const RetryDirectivePrefix = "---- retry:"
+
+// DefaultRetryBackoff is the delay before the first retry of a
+// [RetryPolicy] that sets none.
+const DefaultRetryBackoff = time.Second
+
+// MaxRetryBackoff caps the doubling of a [RetryPolicy] backoff; a backoff
+// set above it is used as is, without doubling.
+const MaxRetryBackoff = time.Minute
+
+// RetryPolicy sets how many times a migration that fails on a lock timeout
+// or a deadlock is retried, and how long to wait before each retry.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero
+	// disables retries.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry, doubled before each
+	// following one up to [MaxRetryBackoff]. Defaults to
+	// [DefaultRetryBackoff].
+	Backoff time.Duration
+}
+
+// Delay returns the delay before the nth retry, counting from 1.
+func (p RetryPolicy) Delay(n int) time.Duration {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	limit := max(backoff, MaxRetryBackoff)
+
+	delay := backoff
+	for i := 1; i < n && delay < limit; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limit)
+}
+
+// RetryPolicy returns the retry policy the migration declares for the
+// given direction with [RetryDirectivePrefix]. It reports false when the
+// migration declares none.
+func (m *Migration) RetryPolicy(dir direction.Direction) (RetryPolicy, bool) {
+	var f *migrateFunc
+
+	switch dir {
+	case direction.DirectionUp:
+		f = m.up
+	case direction.DirectionDown:
+		f = m.down
+	}
+
+	if f == nil || f.retry == nil {
+		return RetryPolicy{}, false
+	}
+
+	return *f.retry, true
+}
+
+// parseRetry parses the content of a retry directive: "<retries>
+// [backoff=<duration>]".
+func parseRetry(s string) (*RetryPolicy, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf(
+			"malformed retry directive, expected: <retries> [backoff=<duration>], got: %q",
+			s,
+		)
+	}
+
+	retries, err := strconv.Atoi(fields[0])
+	if err != nil || retries < 0 {
+		return nil, fmt.Errorf("malformed retry directive, expected a number of retries, got: %q", fields[0])
+	}
+
+	policy := &RetryPolicy{MaxRetries: retries, Backoff: DefaultRetryBackoff}
+
+	if len(fields) == 2 {
+		raw, ok := strings.CutPrefix(fields[1], "backoff=")
+		if !ok {
+			return nil, fmt.Errorf("malformed retry directive, unknown option: %q", fields[1])
+		}
+
+		policy.Backoff, err = time.ParseDuration(raw)
+		if err != nil || policy.Backoff <= 0 {
+			return nil, fmt.Errorf("malformed retry directive, expected a positive backoff, got: %q", raw)
+		}
+	}
+
+	return policy, nil
+}
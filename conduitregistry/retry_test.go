@@ -0,0 +1,109 @@
+package conduitregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse retry directive, when declared in migration", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- retry: 5 backoff=2s ----\nSELECT 1;").
+			WithFile("20230601120000_a.down.sql", "---- retry: 3 ----\nSELECT 1;").
+			WithFile("20230602120000_b.up.sql", "SELECT 1;").
+			Build()
+
+		// Act
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+
+		byKey := make(map[string]*Migration)
+		for _, m := range migrations {
+			byKey[m.Key()] = m
+		}
+
+		up, ok := byKey["20230601120000_a"].RetryPolicy(direction.DirectionUp)
+		assert.True(t, ok)
+		assert.Equal(t, RetryPolicy{MaxRetries: 5, Backoff: 2 * time.Second}, up)
+
+		down, ok := byKey["20230601120000_a"].RetryPolicy(direction.DirectionDown)
+		assert.True(t, ok)
+		assert.Equal(t, RetryPolicy{MaxRetries: 3, Backoff: DefaultRetryBackoff}, down)
+
+		_, ok = byKey["20230602120000_b"].RetryPolicy(direction.DirectionUp)
+		assert.False(t, ok)
+	})
+
+	t.Run("should return error, when directive is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		for _, directive := range []string{
+			"---- retry: ----",
+			"---- retry: many ----",
+			"---- retry: 5 delay=2s ----",
+			"---- retry: 5 backoff=0s ----",
+		} {
+			// Arrange
+			fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+				WithFile("20230601120000_a.up.sql", directive+"\nSELECT 1;").
+				Build()
+
+			// Act
+			_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+			// Assert
+			require.ErrorContains(t, err, "malformed retry directive", directive)
+		}
+	})
+
+	t.Run("should double delay, when retrying again", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		p := RetryPolicy{MaxRetries: 3, Backoff: 2 * time.Second}
+
+		// Act & Assert
+		assert.Equal(t, 2*time.Second, p.Delay(1))
+		assert.Equal(t, 4*time.Second, p.Delay(2))
+		assert.Equal(t, 8*time.Second, p.Delay(3))
+		assert.Equal(t, DefaultRetryBackoff, RetryPolicy{MaxRetries: 1}.Delay(1))
+	})
+
+	t.Run("should cap delay, when retrying many times", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		p := RetryPolicy{MaxRetries: 100, Backoff: 2 * time.Second}
+
+		// Act & Assert
+		assert.Equal(t, 32*time.Second, p.Delay(5))
+		assert.Equal(t, MaxRetryBackoff, p.Delay(6))
+		assert.Equal(t, MaxRetryBackoff, p.Delay(70))
+		assert.Equal(t, MaxRetryBackoff, p.Delay(100))
+	})
+
+	t.Run("should keep backoff, when it exceeds the cap", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		p := RetryPolicy{MaxRetries: 3, Backoff: 2 * time.Minute}
+
+		// Act & Assert
+		assert.Equal(t, 2*time.Minute, p.Delay(1))
+		assert.Equal(t, 2*time.Minute, p.Delay(3))
+	})
+}
@@ -0,0 +1,130 @@
+package conduitregistry
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.inout.gg/conduit/internal/direction"
+)
+
+const (
+	// LockTimeoutDirectivePrefix sets lock_timeout while the migration runs.
+	// Format: ---- lock-timeout: <duration> ----, e.g. 3s.
+	LockTimeoutDirectivePrefix = "---- lock-timeout:"
+
+	// StatementTimeoutDirectivePrefix sets statement_timeout while the
+	// migration runs. Format: ---- statement-timeout: <duration> ----, e.g. 10m.
+	StatementTimeoutDirectivePrefix = "---- statement-timeout:"
+
+	// SetDirectivePrefix sets a configuration parameter while the migration
+	// runs. Format: ---- set: <name>=<value> ----, e.g. work_mem=256MB.
+	SetDirectivePrefix = "---- set:"
+)
+
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// settingNameRe matches configuration parameter names, optionally prefixed
+// by the extension that defines them, such as "pg_trgm.similarity_threshold".
+var settingNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Setting is a Postgres configuration parameter set while a migration runs:
+// with SET LOCAL inside the migration transaction, and with SET on the
+// session otherwise, which is reset once the migration completes.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// LockTimeout returns the lock_timeout setting for d.
+func LockTimeout(d time.Duration) Setting {
+	return Setting{Name: "lock_timeout", Value: formatTimeout(d)}
+}
+
+// StatementTimeout returns the statement_timeout setting for d.
+func StatementTimeout(d time.Duration) Setting {
+	return Setting{Name: "statement_timeout", Value: formatTimeout(d)}
+}
+
+// ParseSetting parses a setting in the form <name>=<value>, as accepted by
+// [SetDirectivePrefix].
+func ParseSetting(s string) (Setting, error) {
+	name, value, ok := strings.Cut(s, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+	if !ok || value == "" {
+		return Setting{}, fmt.Errorf("%w: expected <name>=<value>, got: %q", ErrInvalidSetting, s)
+	}
+
+	if !settingNameRe.MatchString(name) {
+		return Setting{}, fmt.Errorf("%w: malformed parameter name %q", ErrInvalidSetting, name)
+	}
+
+	return Setting{Name: strings.ToLower(name), Value: value}, nil
+}
+
+// Settings returns the settings the migration declares for the given
+// direction with [LockTimeoutDirectivePrefix],
+// [StatementTimeoutDirectivePrefix] and [SetDirectivePrefix] directives.
+func (m *Migration) Settings(dir direction.Direction) []Setting {
+	switch dir {
+	case direction.DirectionUp:
+		return m.up.settings
+	case direction.DirectionDown:
+		return m.down.settings
+	}
+
+	return nil
+}
+
+// parseSettingDirective parses content as a setting directive. It reports
+// false when content is not one.
+func parseSettingDirective(content string) (Setting, bool, error) {
+	for prefix, setting := range map[string]func(time.Duration) Setting{
+		LockTimeoutDirectivePrefix:      LockTimeout,
+		StatementTimeoutDirectivePrefix: StatementTimeout,
+	} {
+		inner, ok := strings.CutPrefix(content, prefix)
+		if !ok {
+			continue
+		}
+
+		raw := strings.TrimSpace(strings.TrimSuffix(inner, "----"))
+
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return Setting{}, true, fmt.Errorf(
+				"%w: malformed %s directive, expected a duration such as 3s, got: %q",
+				ErrInvalidSetting,
+				strings.TrimSuffix(strings.TrimPrefix(prefix, "---- "), ":"),
+				raw,
+			)
+		}
+
+		return setting(d), true, nil
+	}
+
+	inner, ok := strings.CutPrefix(content, SetDirectivePrefix)
+	if !ok {
+		return Setting{}, false, nil
+	}
+
+	s, err := ParseSetting(strings.TrimSuffix(inner, "----"))
+
+	return s, true, err
+}
+
+// formatTimeout formats d in milliseconds, the unit of Postgres timeouts,
+// rounding up so that a positive d does not disable the timeout, as zero
+// does.
+func formatTimeout(d time.Duration) string {
+	ms := d.Milliseconds()
+	if d > 0 && time.Duration(ms)*time.Millisecond < d {
+		ms++
+	}
+
+	return strconv.FormatInt(ms, 10) + "ms"
+}
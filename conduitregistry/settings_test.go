@@ -0,0 +1,106 @@
+package conduitregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse setting directives, when declared in migration", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", `---- lock-timeout: 3s ----
+---- statement-timeout: 10m ----
+---- set: work_mem = 256MB ----
+SELECT 1;`).
+			WithFile("20230601120000_a.down.sql", "---- lock-timeout: 500us ----\nSELECT 1;").
+			Build()
+
+		// Act
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.Equal(t, []Setting{
+			{Name: "lock_timeout", Value: "3000ms"},
+			{Name: "statement_timeout", Value: "600000ms"},
+			{Name: "work_mem", Value: "256MB"},
+		}, migrations[0].Settings(direction.DirectionUp))
+		assert.Equal(t, []Setting{
+			{Name: "lock_timeout", Value: "1ms"},
+		}, migrations[0].Settings(direction.DirectionDown))
+	})
+
+	t.Run("should return error, when directive is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		for _, directive := range []string{
+			"---- lock-timeout: soon ----",
+			"---- statement-timeout: -1s ----",
+			"---- set: work_mem ----",
+			"---- set: work mem=256MB ----",
+		} {
+			// Arrange
+			fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+				WithFile("20230601120000_a.up.sql", directive+"\nSELECT 1;").
+				Build()
+
+			// Act
+			_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+			// Assert
+			require.ErrorIs(t, err, ErrInvalidSetting, directive)
+		}
+	})
+
+	t.Run("should return error, when parameter is set twice", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- lock-timeout: 3s ----\n---- set: lock_timeout=5s ----\nSELECT 1;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidSetting)
+		assert.ErrorContains(t, err, "lock_timeout is set more than once")
+	})
+}
+
+func TestStatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should disable timeout, when duration is zero", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		s := StatementTimeout(0)
+
+		// Assert
+		assert.Equal(t, Setting{Name: "statement_timeout", Value: "0ms"}, s)
+	})
+
+	t.Run("should format in milliseconds", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		s := StatementTimeout(90 * time.Second)
+
+		// Assert
+		assert.Equal(t, "90000ms", s.Value)
+	})
+}
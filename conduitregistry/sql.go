@@ -2,6 +2,7 @@ package conduitregistry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,9 +10,11 @@ import (
 	"strings"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/spf13/afero"
 
 	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/sqltemplate"
 	"go.inout.gg/conduit/pkg/conduitversion"
 	"go.inout.gg/conduit/pkg/sqlsplit"
 )
@@ -27,7 +30,7 @@ const (
 	HazardDirectivePrefix = "---- hazard:"
 )
 
-func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
+func parseSQLMigrationsFromFS(fs afero.Fs, root string, vars map[string]any) ([]*Migration, error) {
 	migrations := make(map[string]*Migration)
 
 	err := afero.Walk(fs, root, func(path string, fileInfo os.FileInfo, err error) error {
@@ -53,6 +56,13 @@ func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
 			return fmt.Errorf("failed to read migration file: %w", err)
 		}
 
+		if sqltemplate.Is(content) {
+			content, err = sqltemplate.Render(filepath.Base(path), content, vars)
+			if err != nil {
+				return fmt.Errorf("failed to render %s: %w", path, err)
+			}
+		}
+
 		stmts, err := sqlsplit.Split(content)
 		if err != nil {
 			return fmt.Errorf("failed to split migration SQL: %w", err)
@@ -63,10 +73,11 @@ func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
 		m, ok := migrations[key]
 		if !ok {
 			m = &Migration{
-				version: info.Version,
-				name:    info.Name,
-				up:      nil,
-				down:    emptyMigrateFunc,
+				version:   info.Version,
+				namespace: "",
+				name:      info.Name,
+				up:        nil,
+				down:      emptyMigrateFunc,
 			}
 			migrations[key] = m
 		}
@@ -81,7 +92,10 @@ func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
 				)
 			}
 
-			m.up = sqlMigrateFunc(stmts)
+			m.up, err = sqlMigrateFunc(stmts)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
 
 		case conduitversion.MigrationDirectionDown:
 			if m.down != emptyMigrateFunc {
@@ -92,7 +106,18 @@ func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
 				)
 			}
 
-			m.down = sqlMigrateFunc(stmts)
+			m.down, err = sqlMigrateFunc(stmts)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+
+			if len(m.down.dependsOn) > 0 {
+				return fmt.Errorf("%s: depends-on directives belong in the up migration", path)
+			}
+
+			if len(m.down.tags) > 0 {
+				return fmt.Errorf("%s: tags directives belong in the up migration", path)
+			}
 		}
 
 		return nil
@@ -113,13 +138,19 @@ func parseSQLMigrationsFromFS(fs afero.Fs, root string) ([]*Migration, error) {
 	return result, nil
 }
 
-func sqlMigrateFunc(stmts []sqlsplit.Stmt) *migrateFunc {
+func sqlMigrateFunc(stmts []sqlsplit.Stmt) (*migrateFunc, error) {
 	useTx := slices.ContainsFunc(stmts, func(stmt sqlsplit.Stmt) bool {
 		return stmt.Type == sqlsplit.StmtTypeComment &&
 			strings.TrimSpace(stmt.Content) == EnableTxDirective
 	})
 
-	var hazards []Hazard
+	var (
+		hazards   []Hazard
+		dependsOn []string
+		settings  []Setting
+		retry     *RetryPolicy
+		tags      []string
+	)
 
 	for _, stmt := range stmts {
 		if stmt.Type != sqlsplit.StmtTypeComment {
@@ -127,6 +158,63 @@ func sqlMigrateFunc(stmts []sqlsplit.Stmt) *migrateFunc {
 		}
 
 		content := strings.TrimSpace(stmt.Content)
+
+		if inner, ok := strings.CutPrefix(content, DependsOnDirectivePrefix); ok {
+			// Parse "---- depends-on: <namespace>/<version>_<name> ----"
+			dep, err := parseDependency(strings.TrimSpace(strings.TrimSuffix(inner, "----")))
+			if err != nil {
+				return nil, err
+			}
+
+			if !slices.Contains(dependsOn, dep) {
+				dependsOn = append(dependsOn, dep)
+			}
+
+			continue
+		}
+
+		if inner, ok := strings.CutPrefix(content, TagsDirectivePrefix); ok {
+			// Parse "---- tags: <tag>[,<tag>...] ----"
+			var err error
+
+			tags, err = parseTags(tags, strings.TrimSuffix(inner, "----"))
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if inner, ok := strings.CutPrefix(content, RetryDirectivePrefix); ok {
+			// Parse "---- retry: <retries> [backoff=<duration>] ----"
+			if retry != nil {
+				return nil, errors.New("retry directive is declared more than once")
+			}
+
+			var err error
+
+			retry, err = parseRetry(strings.TrimSuffix(inner, "----"))
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if setting, ok, err := parseSettingDirective(content); ok {
+			if err != nil {
+				return nil, err
+			}
+
+			if slices.ContainsFunc(settings, func(s Setting) bool { return s.Name == setting.Name }) {
+				return nil, fmt.Errorf("%w: %s is set more than once", ErrInvalidSetting, setting.Name)
+			}
+
+			settings = append(settings, setting)
+
+			continue
+		}
+
 		if !strings.HasPrefix(content, HazardDirectivePrefix) {
 			continue
 		}
@@ -152,48 +240,97 @@ func sqlMigrateFunc(stmts []sqlsplit.Stmt) *migrateFunc {
 	})
 
 	migration := &migrateFunc{
-		useTx:   useTx,
-		hazards: hazards,
-		content: strings.Join(contents, "\n"),
-		fn:      nil,
-		fnx:     nil,
+		useTx:     useTx,
+		hazards:   hazards,
+		dependsOn: dependsOn,
+		settings:  settings,
+		retry:     retry,
+		tags:      tags,
+		stmts:     queryStmts,
+		content:   strings.Join(contents, "\n"),
+		fn:        nil,
+		fnx:       nil,
 	}
 
 	if useTx {
 		migration.fnx = func(ctx context.Context, tx pgx.Tx) error {
-			for _, stmt := range queryStmts {
-				if _, err := tx.Exec(ctx, stmt.Content); err != nil {
-					return fmt.Errorf(
-						"failed to execute migration script: %w\n\n%s",
-						err,
-						stmt.String(),
-					)
-				}
-			}
-
-			return nil
+			return execStmts(ctx, tx, queryStmts, 0, nil)
 		}
 	} else {
 		migration.fn = func(ctx context.Context, conn *pgx.Conn) error {
-			for _, stmt := range queryStmts {
-				_, err := conn.Exec(ctx, stmt.Content)
-				if err != nil {
-					return fmt.Errorf(
-						"failed to execute migration script: %w\n\n%s",
-						err,
-						stmt.String(),
-					)
-				}
+			return execStmts(ctx, conn, queryStmts, 0, nil)
+		}
+	}
+
+	return migration, nil
+}
+
+// execer is implemented by both *pgx.Conn and pgx.Tx.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// execStmts executes stmts on db, skipping the first from of them, and
+// calls done, when set, with the number of statements executed so far after
+// each one succeeds. The [StatementHooks] carried by ctx, if any, are called
+// around each statement.
+func execStmts(
+	ctx context.Context,
+	db execer,
+	stmts []sqlsplit.Stmt,
+	from int,
+	done func(int) error,
+) error {
+	hooks, _ := ctx.Value(statementHooksKey{}).(StatementHooks)
+
+	for i := from; i < len(stmts); i++ {
+		if err := execStmt(ctx, db, stmts[i], hooks); err != nil {
+			return err
+		}
+
+		if done != nil {
+			if err := done(i + 1); err != nil {
+				return err
 			}
+		}
+	}
 
-			return nil
+	return nil
+}
+
+func execStmt(ctx context.Context, db execer, stmt sqlsplit.Stmt, hooks StatementHooks) error {
+	if hooks.Before != nil {
+		if err := hooks.Before(ctx, stmt.Content); err != nil {
+			return err
 		}
 	}
 
-	return migration
+	_, err := db.Exec(ctx, stmt.Content)
+	if err != nil {
+		err = fmt.Errorf("failed to execute migration script: %w\n\n%s", err, stmt.String())
+	}
+
+	if hooks.After != nil {
+		if hookErr := hooks.After(ctx, stmt.Content, err); hookErr != nil {
+			return errors.Join(err, hookErr)
+		}
+	}
+
+	return err
 }
 
 // migrationKey returns a composite key identifying a migration by version and name.
 func migrationKey(v conduitversion.Version, name string) string {
 	return v.String() + "_" + name
 }
+
+// Key returns a composite key identifying a migration by namespace, version
+// and name: "<version>_<name>" in the default namespace and
+// "<namespace>/<version>_<name>" otherwise.
+func Key(namespace, version, name string) string {
+	if namespace == "" {
+		return version + "_" + name
+	}
+
+	return namespace + "/" + version + "_" + name
+}
@@ -24,7 +24,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -52,7 +52,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -81,7 +81,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -110,7 +110,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -139,7 +139,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -167,7 +167,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		_, err := parseSQLMigrationsFromFS(fs, dir)
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.Error(t, err)
@@ -183,7 +183,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		_, err := parseSQLMigrationsFromFS(fs, dir)
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.Error(t, err)
@@ -202,7 +202,7 @@ func TestParseSQLMigrationsFromFS(t *testing.T) {
 			Build()
 
 		// Act
-		migrations, err := parseSQLMigrationsFromFS(fs, dir)
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
 
 		// Assert
 		require.NoError(t, err)
@@ -0,0 +1,40 @@
+package conduitregistry
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TagsDirectivePrefix tags a migration, so that it runs only where one of
+// its tags is selected, e.g. only in development.
+// Format: ---- tags: <tag>[,<tag>...] ----, e.g. ---- tags: dev,seed ----.
+const TagsDirectivePrefix = "---- tags:"
+
+// tagRe matches a single tag.
+var tagRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Tags returns the tags of the migration, as declared with
+// [TagsDirectivePrefix] in its up file.
+func (m *Migration) Tags() []string { return m.up.tags }
+
+// parseTags parses the content of a tags directive, appending the tags not
+// in tags yet.
+func parseTags(tags []string, s string) ([]string, error) {
+	for tag := range strings.SplitSeq(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if !tagRe.MatchString(tag) {
+			return nil, fmt.Errorf(
+				"malformed tags directive, expected: <tag>[,<tag>...] of letters, digits, _ and -, got: %q",
+				strings.TrimSpace(s),
+			)
+		}
+
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,62 @@
+package conduitregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse tags, when declared in up migration", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- tags: dev, seed ----\n---- tags: dev,eu ----\nSELECT 1;").
+			Build()
+
+		// Act
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.Equal(t, []string{"dev", "seed", "eu"}, migrations[0].Tags())
+	})
+
+	t.Run("should return error, when directive is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- tags: dev,,seed ----\nSELECT 1;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorContains(t, err, "malformed tags directive")
+	})
+
+	t.Run("should return error, when directive is in a down file", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "SELECT 1;").
+			WithFile("20230601120000_a.down.sql", "---- tags: dev ----\nSELECT 1;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorContains(t, err, "tags directives belong in the up migration")
+	})
+}
@@ -0,0 +1,21 @@
+package conduitregistry
+
+import "go.inout.gg/conduit/internal/sqltemplate"
+
+// TemplateDirective, when present as a line of a SQL migration file, causes
+// the file to be rendered with text/template, using the variables set with
+// [WithTemplateVars], before it is parsed. Everything else about the
+// migration, such as its checksum and the SQL a dry run shows, is based on
+// the rendered SQL.
+//
+// Besides the variables, templates may use two functions that quote values
+// for SQL: ident, which quotes its arguments as a qualified identifier, e.g.
+// {{ ident .Schema "users" }}, and literal, which quotes a string literal.
+const TemplateDirective = sqltemplate.Directive
+
+// WithTemplateVars sets the variables migrations with [TemplateDirective]
+// are rendered with, e.g. the schema or role names of the host application.
+// A template that refers to a variable not in vars fails to render.
+func WithTemplateVars(vars map[string]any) Option {
+	return func(r *Registry) { r.templateVars = vars }
+}
@@ -0,0 +1,119 @@
+package conduitregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit/internal/direction"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render migration, when template directive is declared", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", `---- template ----
+CREATE TABLE {{ ident .Schema "users" }} (id int);
+COMMENT ON TABLE {{ ident .Schema "users" }} IS {{ literal .Comment }};`).
+			WithFile("20230601120000_a.down.sql", "DROP TABLE {{ .Schema }}.users;").
+			Build()
+
+		// Act
+		migrations, err := parseSQLMigrationsFromFS(fs, dir, map[string]any{
+			"Schema":  "app",
+			"Comment": "it's users",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, migrations, 1)
+		assert.Equal(t, `CREATE TABLE "app"."users" (id int);
+COMMENT ON TABLE "app"."users" IS 'it''s users';`, migrations[0].Content(direction.DirectionUp))
+		assert.Equal(t, "DROP TABLE {{ .Schema }}.users;", migrations[0].Content(direction.DirectionDown))
+	})
+
+	t.Run("should checksum rendered SQL, when variables differ", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- template ----\nCREATE SCHEMA {{ ident .Schema }};").
+			Build()
+
+		// Act
+		a, errA := parseSQLMigrationsFromFS(fs, dir, map[string]any{"Schema": "a"})
+		b, errB := parseSQLMigrationsFromFS(fs, dir, map[string]any{"Schema": "b"})
+
+		// Assert
+		require.NoError(t, errA)
+		require.NoError(t, errB)
+		assert.NotEqual(t, a[0].Content(direction.DirectionUp), b[0].Content(direction.DirectionUp))
+	})
+
+	t.Run("should return error, when variable is missing", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- template ----\nCREATE SCHEMA {{ .Schema }};").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, map[string]any{})
+
+		// Assert
+		require.ErrorContains(t, err, "failed to execute template")
+	})
+
+	t.Run("should return error, when template is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- template ----\nCREATE SCHEMA {{ .Schema ;").
+			Build()
+
+		// Act
+		_, err := parseSQLMigrationsFromFS(fs, dir, nil)
+
+		// Assert
+		require.ErrorContains(t, err, "failed to parse template")
+	})
+
+	t.Run("should panic, when registry is built with missing variables", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- template ----\nCREATE SCHEMA {{ .Schema }};").
+			Build()
+
+		// Act & Assert
+		assert.Panics(t, func() { FromFS(fs, dir) })
+		assert.NotPanics(t, func() {
+			FromFS(fs, dir, WithTemplateVars(map[string]any{"Schema": "app"}))
+		})
+	})
+
+	t.Run("should return error naming file, when registry is loaded with missing variables", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_a.up.sql", "---- template ----\nCREATE SCHEMA {{ .Schema }};").
+			Build()
+
+		// Act
+		_, err := Load(fs, dir)
+
+		// Assert
+		require.ErrorContains(t, err, "20230601120000_a.up.sql")
+		require.ErrorContains(t, err, "failed to execute template")
+	})
+}
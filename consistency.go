@@ -0,0 +1,146 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+// ConsistencyPolicy controls how the Migrator reacts to an inconsistency
+// between the registry and the history table.
+type ConsistencyPolicy string
+
+const (
+	ConsistencyAllow ConsistencyPolicy = "allow" // proceed silently
+	ConsistencyWarn  ConsistencyPolicy = "warn"  // log a warning and proceed
+	ConsistencyError ConsistencyPolicy = "error" // refuse to migrate
+)
+
+var (
+	ErrUnknownConsistencyPolicy = errors.New("unknown consistency policy: expected allow, warn or error")
+	ErrOutOfOrder               = errors.New("out-of-order migrations detected")
+	ErrOrphanedMigrations       = errors.New("orphaned migrations detected")
+)
+
+// ParseConsistencyPolicy parses s as a [ConsistencyPolicy].
+func ParseConsistencyPolicy(s string) (ConsistencyPolicy, error) {
+	switch p := ConsistencyPolicy(s); p {
+	case ConsistencyAllow, ConsistencyWarn, ConsistencyError:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownConsistencyPolicy, s)
+}
+
+// OutOfOrderError is returned when pending migrations are older than the
+// newest applied migration, for example after merging a long-lived branch.
+type OutOfOrderError struct {
+	// Migrations lists the keys of the pending migrations that are older
+	// than LatestApplied.
+	Migrations    []string
+	LatestApplied conduitversion.Version
+}
+
+func (e *OutOfOrderError) Error() string {
+	return fmt.Sprintf(
+		"%s: migrations older than the latest applied version %s:\n  - %s",
+		ErrOutOfOrder,
+		e.LatestApplied.String(),
+		strings.Join(e.Migrations, "\n  - "),
+	)
+}
+
+func (e *OutOfOrderError) Unwrap() error { return ErrOutOfOrder }
+
+// OrphanedMigrationsError is returned when the history table records
+// migrations that are not present in the registry.
+type OrphanedMigrationsError struct {
+	// Migrations lists the keys of the recorded migrations missing from the
+	// registry.
+	Migrations []string
+}
+
+func (e *OrphanedMigrationsError) Error() string {
+	return fmt.Sprintf(
+		"%s: applied migrations missing from the registry:\n  - %s",
+		ErrOrphanedMigrations,
+		strings.Join(e.Migrations, "\n  - "),
+	)
+}
+
+func (e *OrphanedMigrationsError) Unwrap() error { return ErrOrphanedMigrations }
+
+// checkConsistency applies the out-of-order and orphaned policies to the
+// migrations about to run in direction dir.
+func (m *Migrator) checkConsistency(
+	ctx context.Context,
+	report *StatusReport,
+	dir Direction,
+	migrations []*Migration,
+) error {
+	if missing := report.Missing(); len(missing) > 0 {
+		err := &OrphanedMigrationsError{
+			Migrations: sliceutil.Map(missing, func(s *MigrationStatus) string { return s.Key() }),
+		}
+		if err := m.enforce(ctx, m.orphanedPolicy, err, slog.Any("migrations", err.Migrations)); err != nil {
+			return err
+		}
+	}
+
+	if dir != DirectionUp {
+		return nil
+	}
+
+	var latest conduitversion.Version
+
+	for _, s := range report.Migrations {
+		if !s.isPending() && !s.Version.IsRepeatable() && s.Version.Compare(latest) > 0 {
+			latest = s.Version
+		}
+	}
+
+	outOfOrder := sliceutil.Filter(migrations, func(migration *Migration) bool {
+		return migration.Version().Compare(latest) < 0
+	})
+	if len(outOfOrder) > 0 {
+		err := &OutOfOrderError{
+			Migrations:    sliceutil.Map(outOfOrder, func(m *Migration) string { return m.Key() }),
+			LatestApplied: latest,
+		}
+		if err := m.enforce(
+			ctx,
+			m.outOfOrderPolicy,
+			err,
+			slog.Any("migrations", err.Migrations),
+			slog.String("latest_applied", latest.String()),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enforce returns err under [ConsistencyError], logs it under
+// [ConsistencyWarn] and ignores it otherwise.
+func (m *Migrator) enforce(
+	ctx context.Context,
+	policy ConsistencyPolicy,
+	err error,
+	attrs ...any,
+) error {
+	switch policy {
+	case ConsistencyError:
+		return err
+	case ConsistencyWarn:
+		m.logger.WarnContext(ctx, errors.Unwrap(err).Error(), attrs...)
+	case ConsistencyAllow:
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package conduit_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Consistency(t *testing.T) {
+	t.Parallel()
+
+	applied := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		"20230603120000_create_c.up.sql": "CREATE TABLE c (id INT);",
+	}
+
+	migrateUp := func(t *testing.T, conn *pgx.Conn, files map[string]string, opts ...conduit.Option) error {
+		t.Helper()
+
+		opts = append(opts, conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := conduit.NewMigrator(opts...).Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		testutil.CollectSeq2(t, seq)
+
+		return nil
+	}
+
+	t.Run("should return OutOfOrderError, when pending migration is older than latest applied", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		require.NoError(t, migrateUp(t, conn, applied))
+
+		files := maps.Clone(applied)
+		files["20230602120000_create_b.up.sql"] = "CREATE TABLE b (id INT);"
+
+		// Act
+		err := migrateUp(t, conn, files, conduit.WithOutOfOrderPolicy(conduit.ConsistencyError))
+
+		// Assert
+		var outOfOrderErr *conduit.OutOfOrderError
+		require.ErrorAs(t, err, &outOfOrderErr)
+		require.ErrorIs(t, err, conduit.ErrOutOfOrder)
+		assert.Equal(t, []string{"20230602120000_create_b"}, outOfOrderErr.Migrations)
+		assert.Equal(t, "20230603120000", outOfOrderErr.LatestApplied.String())
+	})
+
+	t.Run("should apply out-of-order migration, when policy is warn", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		require.NoError(t, migrateUp(t, conn, applied))
+
+		files := maps.Clone(applied)
+		files["20230602120000_create_b.up.sql"] = "CREATE TABLE b (id INT);"
+
+		// Act
+		err := migrateUp(t, conn, files, conduit.WithOutOfOrderPolicy(conduit.ConsistencyWarn))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, appliedMigrations(t, pool), 3)
+	})
+
+	t.Run("should return OrphanedMigrationsError, when applied migration is missing from registry", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		require.NoError(t, migrateUp(t, conn, applied))
+
+		files := map[string]string{
+			"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+			"20230604120000_create_d.up.sql": "CREATE TABLE d (id INT);",
+		}
+
+		// Act
+		err := migrateUp(t, conn, files, conduit.WithOrphanedPolicy(conduit.ConsistencyError))
+
+		// Assert
+		var orphanedErr *conduit.OrphanedMigrationsError
+		require.ErrorAs(t, err, &orphanedErr)
+		assert.Equal(t, []string{"20230603120000_create_c"}, orphanedErr.Migrations)
+	})
+}
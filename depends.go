@@ -0,0 +1,56 @@
+package conduit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrUnmetDependency = errors.New("migration dependency not met")
+
+// checkDependencies returns [ErrUnmetDependency] when running migrations in
+// direction dir would break a depends-on directive: an up migration whose
+// dependency is neither applied nor run before it, or a down migration
+// that an applied migration, not rolled back before it, depends on.
+//
+// migrations are in run order, and report is the status before the run.
+func checkDependencies(report *StatusReport, dir Direction, migrations []*Migration) error {
+	applied := make(map[string]bool, len(report.Migrations))
+	for _, s := range report.Migrations {
+		applied[s.Key()] = s.State == MigrationStateApplied || s.Outdated()
+	}
+
+	for _, migration := range migrations {
+		switch dir {
+		case DirectionUp:
+			for _, dep := range migration.DependsOn() {
+				if !applied[dep] {
+					return fmt.Errorf(
+						"%w: %s depends on %s, which is not applied",
+						ErrUnmetDependency,
+						migration.Key(),
+						dep,
+					)
+				}
+			}
+
+			applied[migration.Key()] = true
+
+		case DirectionDown:
+			for _, s := range report.Migrations {
+				if applied[s.Key()] && s.Migration != nil && slices.Contains(s.Migration.DependsOn(), migration.Key()) {
+					return fmt.Errorf(
+						"%w: %s is depended on by %s, which stays applied",
+						ErrUnmetDependency,
+						migration.Key(),
+						s.Key(),
+					)
+				}
+			}
+
+			applied[migration.Key()] = false
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package conduit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+func TestMigrator_Migrate_DependsOn(t *testing.T) {
+	t.Parallel()
+
+	// The app migration is older than the shield migration it depends on.
+	newRegistry := func(t *testing.T) *conduitregistry.Registry {
+		t.Helper()
+
+		shieldFS, _, shieldDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230605120000_create_users.up.sql", "CREATE TABLE users (id INT PRIMARY KEY);").
+			WithFile("20230605120000_create_users.down.sql", "DROP TABLE users;").
+			Build()
+		appFS, _, appDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_create_posts.up.sql", `---- depends-on: shield/20230605120000_create_users ----
+CREATE TABLE posts (id INT, user_id INT REFERENCES users (id));`).
+			WithFile("20230601120000_create_posts.down.sql", "DROP TABLE posts;").
+			Build()
+
+		r, err := conduitregistry.Compose(
+			conduitregistry.FromFS(shieldFS, shieldDir, conduitregistry.WithNamespace("shield")),
+			conduitregistry.FromFS(appFS, appDir),
+		)
+		require.NoError(t, err)
+
+		return r
+	}
+
+	t.Run("should apply migrations after their dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		assert.Equal(t, []string{
+			"shield/20230605120000_create_users",
+			"20230601120000_create_posts",
+		}, sliceutil.Map(results, func(r *conduit.MigrationResult) string { return r.Key() }))
+	})
+
+	t.Run("should roll back migrations before their dependencies", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t)))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		seq, err = m.Migrate(t.Context(), conduit.DirectionDown, conn, &conduit.MigrateOptions{Steps: conduit.AllSteps})
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		assert.Equal(t, []string{
+			"20230601120000_create_posts",
+			"shield/20230605120000_create_users",
+		}, sliceutil.Map(results, func(r *conduit.MigrationResult) string { return r.Key() }))
+	})
+
+	t.Run("should return error, when target leaves a dependency out", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t)))
+
+		// Act
+		_, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, &conduit.MigrateOptions{
+			To: conduitversion.NewFromTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)),
+		})
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrUnmetDependency)
+	})
+}
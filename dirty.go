@@ -0,0 +1,220 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit/internal/dbsqlc"
+)
+
+var (
+	ErrDirtyMigration    = errors.New("a migration failed part-way and left the database dirty")
+	ErrNotDirty          = errors.New("no migration is dirty")
+	ErrResumeUnsupported = errors.New("executor does not support resuming migrations")
+)
+
+// DirtyMigrationError is returned when a migration that runs outside a
+// transaction failed part-way, leaving the database half-migrated. No
+// migration runs until it is resumed with [Migrator.Resume] or resolved
+// with [Migrator.Resolve].
+type DirtyMigrationError struct {
+	// Migration is the key of the dirty migration.
+	Migration string
+
+	// Direction is the direction the migration was running in.
+	Direction Direction
+
+	// LastStatement is the number of statements that succeeded before the
+	// failure, which is also the index of the statement that failed.
+	LastStatement int
+}
+
+func (e *DirtyMigrationError) Error() string {
+	return fmt.Sprintf(
+		"%s: %s (%s) stopped after %d statements",
+		ErrDirtyMigration,
+		e.Migration,
+		e.Direction,
+		e.LastStatement,
+	)
+}
+
+func (e *DirtyMigrationError) Unwrap() error { return ErrDirtyMigration }
+
+// resumableExecutor is implemented by executors that can run a migration
+// starting from a given statement, so that a dirty migration can be resumed.
+type resumableExecutor interface {
+	resume(
+		ctx context.Context,
+		migration *Migration,
+		dir Direction,
+		conn *pgx.Conn,
+		from int,
+	) (MigrationResult, error)
+}
+
+// checkDirty returns a [*DirtyMigrationError] when report has a dirty
+// migration.
+func checkDirty(report *StatusReport) error {
+	dirty := report.Dirty()
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	return dirty[0].dirtyError()
+}
+
+// Resume runs the remaining statements of the dirty migration, starting from
+// the statement that failed, and records it as if the failed run had
+// succeeded. The migration file may be edited before resuming, for example
+// to fix the failed statement, as long as the statements that already ran
+// keep their positions.
+//
+// When no migration is dirty, [ErrNotDirty] is returned. Resume only
+// finishes the dirty migration; call [Migrator.Migrate] afterwards to apply
+// the rest.
+func (m *Migrator) Resume(ctx context.Context, db DB) (*MigrationResult, error) {
+	executor, ok := m.executor.(resumableExecutor)
+	if !ok {
+		return nil, ErrResumeUnsupported
+	}
+
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = s.context(ctx)
+
+	if err := m.lockHistory(ctx, s); err != nil {
+		s.close()
+
+		return nil, err
+	}
+	defer m.unlock(ctx, s)
+
+	dirty, err := m.dirtyMigration(ctx, s.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dirty.Migration == nil {
+		return nil, fmt.Errorf("failed to resume %s: migration is missing from the registry", dirty.Key())
+	}
+
+	dir := dirty.DirtyDirection
+
+	if err := m.hooks.beforeRun(ctx, s.conn, dir); err != nil {
+		return nil, err
+	}
+
+	ctx = withDefaultSettings(ctx, m.settings)
+	ctx = withRetryPolicy(ctx, m.retryPolicy)
+
+	start := time.Now()
+	result, err := m.hooks.execute(
+		ctx, s.conn, dirty.Migration, dir,
+		func(ctx context.Context) (MigrationResult, error) {
+			return executor.resume(ctx, dirty.Migration, dir, s.conn, dirty.LastStatement)
+		},
+	)
+	m.observer.ObserveMigration(dirty.Migration, dir, time.Since(start), err)
+
+	if err == nil && m.verifySchema {
+		err = m.verify(ctx, s.conn, dirty.Migration, dir)
+	}
+
+	if hookErr := m.hooks.afterRun(ctx, s.conn, dir, err); hookErr != nil {
+		err = errors.Join(err, hookErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Resolve clears the dirty state once the remaining statements of the dirty
+// migration were applied, or reverted, by hand, and returns the key of the
+// migration. The migration is recorded as if its failed run had succeeded:
+// a dirty up migration is recorded as applied, with the schema hash of the
+// live schema, and a dirty down migration is removed from the history.
+//
+// To instead record a dirty up migration as never applied, use
+// [Migrator.MarkUnapplied]. When no migration is dirty, [ErrNotDirty] is
+// returned.
+func (m *Migrator) Resolve(ctx context.Context, db DB) (string, error) {
+	var key string
+
+	err := m.withHistoryTx(ctx, db, func(conn *pgx.Conn, tx pgx.Tx) error {
+		dirty, err := m.dirtyMigration(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		history := dbsqlc.WithTable(tx, m.table)
+		key = dirty.Key()
+
+		switch dirty.DirtyDirection {
+		case DirectionUp:
+			hash, err := m.schemaHash(ctx, conn)
+			if err != nil {
+				return err
+			}
+
+			checksum := dirty.Checksum
+			if dirty.Migration != nil {
+				checksum = dirty.Migration.Checksum(DirectionUp)
+			}
+
+			err = dbsqlc.New().ApplyMigration(ctx, history, dbsqlc.ApplyMigrationParams{
+				Namespace: dirty.Namespace,
+				Version:   dirty.Version.String(),
+				Name:      dirty.Name,
+				Hash:      hash,
+				Checksum:  checksum,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to resolve migration %s: %w", key, err)
+			}
+
+		case DirectionDown:
+			err := dbsqlc.New().RollbackMigration(ctx, history, dbsqlc.RollbackMigrationParams{
+				Namespace: dirty.Namespace,
+				Version:   dirty.Version.String(),
+				Name:      dirty.Name,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to resolve migration %s: %w", key, err)
+			}
+		}
+
+		return writeLog(ctx, tx, m.table, newMarkedLogEntry(dirty, dirty.DirtyDirection))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// dirtyMigration returns the status of the dirty migration, or
+// [ErrNotDirty].
+func (m *Migrator) dirtyMigration(ctx context.Context, conn *pgx.Conn) (*MigrationStatus, error) {
+	report, err := m.Status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	dirty := report.Dirty()
+	if len(dirty) == 0 {
+		return nil, ErrNotDirty
+	}
+
+	return dirty[0], nil
+}
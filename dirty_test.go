@@ -0,0 +1,147 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Dirty(t *testing.T) {
+	t.Parallel()
+
+	failing := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\nSELECT 1/0;\nCREATE TABLE c (id INT);",
+		"20230602120000_create_d.up.sql": "CREATE TABLE d (id INT);",
+	}
+	fixed := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\nSELECT 1;\nCREATE TABLE c (id INT);",
+		"20230602120000_create_d.up.sql": "CREATE TABLE d (id INT);",
+	}
+
+	// fail runs the failing migrations, leaving 20230601120000_create_a
+	// dirty after two statements.
+	fail := func(t *testing.T, conn *pgx.Conn) {
+		t.Helper()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, failing)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+
+		for _, err := range seq {
+			if err != nil {
+				return
+			}
+		}
+
+		t.Fatal("expected migration to fail")
+	}
+
+	t.Run("should refuse to migrate, when a migration is dirty", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		fail(t, conn)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)))
+
+		// Act
+		_, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		var dirtyErr *conduit.DirtyMigrationError
+		require.ErrorAs(t, err, &dirtyErr)
+		assert.Equal(t, "20230601120000_create_a", dirtyErr.Migration)
+		assert.Equal(t, conduit.DirectionUp, dirtyErr.Direction)
+		assert.Equal(t, 2, dirtyErr.LastStatement)
+	})
+
+	t.Run("should report dirty state, when a migration failed part-way", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		fail(t, conn)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, failing)))
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, report.Dirty(), 1)
+		assert.Equal(t, 2, report.Dirty()[0].LastStatement)
+		assert.Empty(t, report.Applied())
+	})
+
+	t.Run("should run from the failed statement, when resumed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		fail(t, conn)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)))
+
+		// Act
+		result, err := m.Resume(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "20230601120000_create_a", result.Key())
+		assert.True(t, testutil.TableExists(t, pool, "c"))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		assert.Len(t, testutil.CollectSeq2(t, seq), 1)
+		assert.Len(t, appliedMigrations(t, pool), 2)
+	})
+
+	t.Run("should record migration as applied, when resolved", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		fail(t, conn)
+
+		_, err := conn.Exec(t.Context(), "CREATE TABLE c (id INT);")
+		require.NoError(t, err)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)))
+
+		// Act
+		key, err := m.Resolve(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "20230601120000_create_a", key)
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Empty(t, report.Dirty())
+		assert.Len(t, report.Applied(), 1)
+		assert.Len(t, appliedMigrations(t, pool), 1)
+	})
+
+	t.Run("should return error, when nothing is dirty", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, fixed)))
+
+		// Act
+		_, err := m.Resume(t.Context(), conn)
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrNotDirty)
+	})
+}
@@ -0,0 +1,146 @@
+package conduit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.inout.gg/conduit/internal/tracing"
+	"go.inout.gg/conduit/pkg/pgdiff"
+	"go.inout.gg/conduit/pkg/sqlsplit"
+)
+
+// SchemaDriftError is returned when the live schema no longer matches the
+// schema hash recorded by the latest applied migration, i.e. the schema was
+// changed outside of migrations.
+type SchemaDriftError struct {
+	// Report lists the objects that differ and the DDL that reconciles
+	// them. It is nil when the report could not be built, for example
+	// because the database user may not create the temporary database it
+	// needs.
+	Report *DriftReport
+
+	// Expected is the schema hash recorded by the latest applied migration.
+	Expected string
+
+	// Actual is the schema hash of the live schema.
+	Actual string
+}
+
+func (e *SchemaDriftError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: expected hash %s, got %s", ErrSchemaDrift, e.Expected, e.Actual)
+
+	if e.Report != nil {
+		for _, o := range e.Report.Objects {
+			fmt.Fprintf(&b, "\n  - %s", formatDriftedObject(o))
+		}
+	}
+
+	return b.String()
+}
+
+func (e *SchemaDriftError) Unwrap() error { return ErrSchemaDrift }
+
+// DriftReport lists the differences between the live schema and the schema
+// the applied migrations describe.
+type DriftReport struct {
+	pgdiff.DriftReport
+
+	// Skipped lists the keys of applied migrations that could not be
+	// replayed to build the expected schema: Go migrations, which have no
+	// SQL, migrations missing from the registry and repeatable migrations
+	// changed since they were applied. Objects they create are reported as
+	// unexpected, or modified.
+	Skipped []string
+}
+
+// Drift compares the live schema with the schema the applied migrations
+// describe, which is built by replaying them in a temporary database on the
+// same instance. Unlike the schema drift check, which compares schema
+// hashes, Drift reports which objects differ and the DDL that reconciles
+// them; the database user must be allowed to create databases.
+//
+// Drift neither takes the advisory lock nor changes the database.
+func (m *Migrator) Drift(ctx context.Context, db DB) (report *DriftReport, err error) {
+	ctx, span := m.tracer.Start(ctx, "conduit.drift")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	return m.driftReport(ctx, s.conn)
+}
+
+// driftReport builds the [DriftReport] of the database conn is connected to.
+func (m *Migrator) driftReport(ctx context.Context, conn *pgx.Conn) (*DriftReport, error) {
+	status, err := m.Status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stmts   []sqlsplit.Stmt
+		skipped []string
+	)
+
+	// The report lists migrations in the order they are applied, which is
+	// the order they are replayed in.
+	for _, s := range status.Migrations {
+		if s.Outdated() {
+			// The content it was applied with is gone.
+			skipped = append(skipped, s.Key())
+			continue
+		}
+
+		if s.isPending() {
+			continue
+		}
+
+		content := ""
+		if s.Migration != nil {
+			content = s.Migration.Content(DirectionUp)
+		}
+
+		if content == "" {
+			skipped = append(skipped, s.Key())
+			continue
+		}
+
+		migrationStmts, err := sqlsplit.Split([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse migration %s: %w", s.Key(), err)
+		}
+
+		stmts = append(stmts, migrationStmts...)
+	}
+
+	report, err := pgdiff.Drift(
+		ctx,
+		conn.Config(),
+		stmts,
+		m.excludeSchemas,
+		pgdiff.WithHistoryTable(m.table.Schema, m.table.Name),
+		pgdiff.WithTracerProvider(trace.SpanFromContext(ctx).TracerProvider()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schema drift report: %w", err)
+	}
+
+	return &DriftReport{DriftReport: report, Skipped: skipped}, nil
+}
+
+func formatDriftedObject(o pgdiff.DriftedObject) string {
+	if o.Name == "" {
+		return fmt.Sprintf("%s %s", o.Change, o.Kind)
+	}
+
+	return fmt.Sprintf("%s %s %s", o.Change, o.Kind, o.Name)
+}
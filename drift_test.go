@@ -0,0 +1,123 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/pgdiff"
+)
+
+func TestMigrator_Drift(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+	}
+
+	// drift applies the first migration and changes the schema by hand.
+	drift := func(t *testing.T, pool *pgxpool.Pool, conn *pgx.Conn) {
+		t.Helper()
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": files["20230601120000_create_a.up.sql"],
+		})))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		_, err = pool.Exec(t.Context(), "ALTER TABLE a ADD COLUMN name TEXT; CREATE TABLE manual (id INT);")
+		require.NoError(t, err)
+	}
+
+	wantObjects := []pgdiff.DriftedObject{
+		{Kind: pgdiff.ObjectKindTable, Name: "public.manual", Change: pgdiff.ObjectChangeUnexpected},
+		{Kind: pgdiff.ObjectKindColumn, Name: "public.a.name", Change: pgdiff.ObjectChangeUnexpected},
+	}
+
+	t.Run("should report drifted objects and reconciling DDL", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		drift(t, pool, conn)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		report, err := m.Drift(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, report.HasDrift())
+		assert.ElementsMatch(t, wantObjects, report.Objects)
+		assert.Empty(t, report.Skipped)
+	})
+
+	t.Run("should leave out excluded schemas, when schemas are excluded", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		testutil.Exec(t, pool, "CREATE SCHEMA audit; CREATE TABLE audit.events (id INT);")
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithExcludeSchemas("audit"),
+		)
+
+		// Act
+		report, err := m.Drift(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+	})
+
+	t.Run("should attach the report to the drift error, when migrating", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		drift(t, pool, conn)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		_, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		var driftErr *conduit.SchemaDriftError
+		require.ErrorAs(t, err, &driftErr)
+		require.ErrorIs(t, err, conduit.ErrSchemaDrift)
+		require.NotNil(t, driftErr.Report)
+		assert.ElementsMatch(t, wantObjects, driftErr.Report.Objects)
+	})
+
+	t.Run("should report no drift, when the schema matches the migrations", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		report, err := m.Drift(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+	})
+}
@@ -2,21 +2,31 @@ package conduit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/stripe/pg-schema-diff/pkg/schema"
 	"go.inout.gg/foundations/must"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.inout.gg/conduit/conduitregistry"
 	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/tracing"
 	"go.inout.gg/conduit/pkg/stopwatch"
 )
 
 // MigrationExecutor executes a single migration.
+//
+// The connection passed to Execute is the dedicated session the Migrator
+// holds the advisory lock on, whichever [DB] was passed to Migrate. When
+// Migrate runs inside a caller-supplied transaction, the session is already
+// in that transaction.
 type MigrationExecutor interface {
 	Execute(
 		context.Context,
@@ -26,9 +36,21 @@ type MigrationExecutor interface {
 	) (MigrationResult, error)
 }
 
+// tableScopedExecutor is implemented by executors that record migrations in
+// the history table, so that the Migrator can point them at its own table
+// and tell them whether to record schema hashes.
+type tableScopedExecutor interface {
+	withHistory(table migrations.Table, skipSchemaHash bool) MigrationExecutor
+}
+
 // NewLiveExecutor returns an executor that applies migrations to the database.
 func NewLiveExecutor(logger *slog.Logger, sw stopwatch.Stopwatch) MigrationExecutor {
-	return &liveExecutor{logger: logger, sw: sw}
+	return &liveExecutor{
+		logger:         logger,
+		sw:             sw,
+		table:          migrations.NewTable("", migrations.DefaultTable),
+		skipSchemaHash: false,
+	}
 }
 
 // NewDryRunExecutor returns an executor that logs migrations to w without
@@ -38,10 +60,22 @@ func NewDryRunExecutor(w io.Writer, verbose bool) MigrationExecutor {
 	return &dryRunExecutor{w: w, verbose: verbose}
 }
 
+// isDryRun reports whether e only previews migrations.
+func isDryRun(e MigrationExecutor) bool {
+	_, ok := e.(*dryRunExecutor)
+	return ok
+}
+
 // liveExecutor applies migrations to the database.
 type liveExecutor struct {
-	logger *slog.Logger
-	sw     stopwatch.Stopwatch
+	logger         *slog.Logger
+	sw             stopwatch.Stopwatch
+	table          migrations.Table
+	skipSchemaHash bool
+}
+
+func (e *liveExecutor) withHistory(table migrations.Table, skipSchemaHash bool) MigrationExecutor {
+	return &liveExecutor{logger: e.logger, sw: e.sw, table: table, skipSchemaHash: skipSchemaHash}
 }
 
 func (e *liveExecutor) Execute(
@@ -49,8 +83,84 @@ func (e *liveExecutor) Execute(
 	migration *conduitregistry.Migration,
 	dir Direction,
 	conn *pgx.Conn,
+) (MigrationResult, error) {
+	return e.execute(ctx, migration, dir, conn, 0)
+}
+
+func (e *liveExecutor) resume(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+	conn *pgx.Conn,
+	from int,
+) (MigrationResult, error) {
+	if must.Must(migration.UseTx(dir)) {
+		return MigrationResult{}, fmt.Errorf(
+			"failed to resume migration %s: %w: it now runs in a transaction",
+			migration.Key(),
+			conduitregistry.ErrNotResumable,
+		)
+	}
+
+	return e.execute(ctx, migration, dir, conn, from)
+}
+
+// execute applies migration, skipping the first from statements, records it
+// in the history table and logs the run, within a span describing the
+// migration. The run is logged on a best-effort basis.
+func (e *liveExecutor) execute(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+	conn *pgx.Conn,
+	from int,
+) (MigrationResult, error) {
+	ctx, span := tracing.FromContext(ctx).Start(
+		ctx,
+		"conduit.migration",
+		trace.WithAttributes(migrationAttributes(migration, dir)...),
+	)
+
+	started := time.Now()
+	result, err := e.apply(ctx, migration, dir, conn, from)
+
+	// The migration is already recorded, so failing to log it must not
+	// fail it.
+	if logErr := writeLog(ctx, conn, e.table, newLogEntry(ctx, migration, dir, started, err)); logErr != nil {
+		e.logger.WarnContext(
+			ctx,
+			"failed to log migration",
+			slog.String("migration", migration.Key()),
+			slog.Any("error", logErr),
+		)
+	}
+
+	if err == nil {
+		span.SetAttributes(attrAttempts.Int(len(result.Attempts)))
+	}
+
+	tracing.End(span, err)
+
+	return result, err
+}
+
+// apply applies migration, skipping the first from statements, and records
+// it in the history table.
+//
+// Migrations that run outside a transaction are marked dirty before their
+// first statement runs, and their progress is recorded after each statement,
+// so that a failure part-way leaves a record to resume from. The settings
+// of the migration are set with SET on the session for such migrations, and
+// with SET LOCAL otherwise; the session is reset once the migration ends.
+func (e *liveExecutor) apply(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+	conn *pgx.Conn,
+	from int,
 ) (MigrationResult, error) {
 	inTx := must.Must(migration.UseTx(dir))
+	settings := migrationSettings(ctx, migration, dir)
 
 	e.logger.DebugContext(
 		ctx,
@@ -58,25 +168,38 @@ func (e *liveExecutor) Execute(
 		slog.String("direction", string(dir)),
 		slog.Group(
 			"migration",
+			slog.String("namespace", migration.Namespace()),
 			slog.String("version", migration.Version().String()),
 			slog.String("name", migration.Name()),
 		),
 		slog.Bool("transacting", inTx),
+		slog.Int("from_statement", from),
+		slog.Any("settings", settings),
 	)
 
 	stop := e.sw.Start()
 
-	var err error
-	if inTx {
-		err = applyMigrationTx(ctx, migration, dir, conn)
-	} else {
-		err = migration.Apply(ctx, dir, conn)
-	}
+	history := dbsqlc.WithTable(conn, e.table)
 
+	attempts, err := e.applyAttempts(ctx, migration, dir, conn, history, from, settings)
 	if err != nil {
+		if !inTx && len(settings) > 0 {
+			// Do not leave the settings on the session.
+			_ = dbsqlc.New().ResetConn(ctx, conn)
+		}
+
+		if len(attempts) > 1 {
+			return MigrationResult{}, fmt.Errorf(
+				"failed to apply migration %s after %d attempts: %w",
+				migration.Key(),
+				len(attempts),
+				err,
+			)
+		}
+
 		return MigrationResult{}, fmt.Errorf(
 			"failed to apply migration %s: %w",
-			migration.Version().String(),
+			migration.Key(),
 			err,
 		)
 	}
@@ -84,33 +207,54 @@ func (e *liveExecutor) Execute(
 	duration := stop()
 	result := MigrationResult{
 		DurationTotal: duration,
+		Direction:     dir,
 		Version:       migration.Version(),
+		Namespace:     migration.Namespace(),
 		Name:          migration.Name(),
+		Attempts:      attempts,
 	}
 
 	switch dir {
 	case DirectionDown:
-		err = dbsqlc.New().RollbackMigration(ctx, conn, dbsqlc.RollbackMigrationParams{
-			Version: result.Version.String(),
-			Name:    result.Name,
+		err = dbsqlc.New().RollbackMigration(ctx, history, dbsqlc.RollbackMigrationParams{
+			Namespace: result.Namespace,
+			Version:   result.Version.String(),
+			Name:      result.Name,
 		})
 
 	case DirectionUp:
 		var schemaHash string
 
-		schemaHash, err = computeSchemaHash(ctx, conn)
-		if err != nil {
-			return MigrationResult{}, fmt.Errorf(
-				"failed to compute schema hash after migration %s: %w",
-				migration.Version().String(),
-				err,
-			)
+		if !e.skipSchemaHash {
+			schemaHash, err = computeSchemaHash(ctx, conn)
+			if err != nil {
+				return MigrationResult{}, fmt.Errorf(
+					"failed to compute schema hash after migration %s: %w",
+					migration.Version().String(),
+					err,
+				)
+			}
 		}
 
-		err = dbsqlc.New().ApplyMigration(ctx, conn, dbsqlc.ApplyMigrationParams{
-			Version: result.Version.String(),
-			Name:    result.Name,
-			Hash:    schemaHash,
+		if migration.Version().IsRepeatable() {
+			// Record a reapplied repeatable migration anew, so that its
+			// schema hash is the latest one.
+			err = dbsqlc.New().RollbackMigration(ctx, history, dbsqlc.RollbackMigrationParams{
+				Namespace: result.Namespace,
+				Version:   result.Version.String(),
+				Name:      result.Name,
+			})
+			if err != nil {
+				break
+			}
+		}
+
+		err = dbsqlc.New().ApplyMigration(ctx, history, dbsqlc.ApplyMigrationParams{
+			Namespace: result.Namespace,
+			Version:   result.Version.String(),
+			Name:      result.Name,
+			Hash:      schemaHash,
+			Checksum:  migration.Checksum(dir),
 		})
 	}
 
@@ -123,6 +267,75 @@ func (e *liveExecutor) Execute(
 	return result, nil
 }
 
+// applyAttempts applies migration, skipping the first from statements, as
+// many times as its retry policy allows for failures that another attempt
+// may not run into, and returns the attempts made.
+//
+// A migration that runs outside a transaction is retried only if no
+// statement succeeded in the failed attempt, and never inside a
+// caller-supplied transaction, which the failure aborts.
+func (e *liveExecutor) applyAttempts(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+	conn *pgx.Conn,
+	history dbsqlc.DBTX,
+	from int,
+	settings []Setting,
+) ([]MigrationAttempt, error) {
+	inTx := must.Must(migration.UseTx(dir))
+	policy := migrationRetryPolicy(ctx, migration, dir)
+	_, inCallerTx := callerTx(ctx)
+
+	var attempts []MigrationAttempt
+
+	for {
+		stop := e.sw.Start()
+		progress := from
+
+		var err error
+		if inTx {
+			err = applyMigrationTx(ctx, migration, dir, conn, settings)
+		} else {
+			err = applySettings(ctx, conn, settings, false)
+			if err == nil {
+				progress, err = applyMigrationTracked(ctx, migration, dir, conn, history, from)
+			}
+		}
+
+		attempts = append(attempts, MigrationAttempt{Err: err, Duration: stop()})
+
+		// Go migrations report no progress, so a failure may come after
+		// statements that succeeded.
+		retryable := inTx || (!inCallerTx && progress == from && migration.Content(dir) != "")
+
+		if err == nil || !retryable || !isRetryable(err) || len(attempts) > policy.MaxRetries {
+			return attempts, err
+		}
+
+		delay := policy.Delay(len(attempts))
+
+		e.logger.WarnContext(
+			ctx,
+			"retrying migration",
+			slog.String("migration", migration.Key()),
+			slog.Int("attempt", len(attempts)+1),
+			slog.Duration("backoff", delay),
+			slog.Any("error", err),
+		)
+		trace.SpanFromContext(ctx).AddEvent(
+			"conduit.retry",
+			trace.WithAttributes(attrAttempts.Int(len(attempts)), attrBackoff.String(delay.String())),
+		)
+
+		select {
+		case <-ctx.Done():
+			return attempts, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
 // dryRunExecutor logs migrations without applying them.
 type dryRunExecutor struct {
 	w       io.Writer
@@ -135,7 +348,7 @@ func (e *dryRunExecutor) Execute(
 	dir Direction,
 	_ *pgx.Conn,
 ) (MigrationResult, error) {
-	fmt.Fprintf(e.w, "%s_%s\n", migration.Version().String(), migration.Name())
+	fmt.Fprintf(e.w, "%s\n", migration.Key())
 
 	if e.verbose {
 		fmt.Fprintf(e.w, "%s\n", migration.Content(dir))
@@ -143,24 +356,39 @@ func (e *dryRunExecutor) Execute(
 
 	//nolint:exhaustruct
 	return MigrationResult{
-		Version: migration.Version(),
-		Name:    migration.Name(),
+		Version:   migration.Version(),
+		Direction: dir,
+		Namespace: migration.Namespace(),
+		Name:      migration.Name(),
 	}, nil
 }
 
+// applyMigrationTx applies a migration that runs inside a transaction, with
+// settings set with SET LOCAL.
 func applyMigrationTx(
 	ctx context.Context,
 	migration *conduitregistry.Migration,
 	dir Direction,
 	conn *pgx.Conn,
+	settings []Setting,
 ) error {
-	tx, err := conn.Begin(ctx)
+	begin := conn.Begin
+	if outer, ok := callerTx(ctx); ok {
+		// Nest inside the caller-supplied transaction as a savepoint.
+		begin = outer.Begin
+	}
+
+	tx, err := begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to open transaction: %w", err)
 	}
 
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	if err := applySettings(ctx, tx, settings, true); err != nil {
+		return err
+	}
+
 	if err := migration.ApplyTx(ctx, dir, tx); err != nil {
 		//nolint:wrapcheck
 		return err
@@ -173,14 +401,77 @@ func applyMigrationTx(
 	return nil
 }
 
+// applyMigrationTracked applies a migration that runs outside a transaction,
+// recording it as dirty until it completes. It returns the number of
+// statements run so far, including the first from skipped ones.
+func applyMigrationTracked(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+	conn *pgx.Conn,
+	history dbsqlc.DBTX,
+	from int,
+) (int, error) {
+	q := dbsqlc.New()
+	progress := from
+
+	if from == 0 {
+		if err := q.MarkMigrationDirty(ctx, history, dbsqlc.MarkMigrationDirtyParams{
+			Namespace:      migration.Namespace(),
+			Version:        migration.Version().String(),
+			Name:           migration.Name(),
+			Checksum:       migration.Checksum(DirectionUp),
+			DirtyDirection: string(dir),
+		}); err != nil {
+			return progress, fmt.Errorf("failed to record migration progress: %w", err)
+		}
+	}
+
+	err := migration.ApplyFrom(ctx, dir, conn, from, func(n int) error {
+		progress = n
+
+		if err := q.UpdateMigrationProgress(ctx, history, dbsqlc.UpdateMigrationProgressParams{
+			LastStatement: int32(n), //nolint:gosec
+			Namespace:     migration.Namespace(),
+			Version:       migration.Version().String(),
+			Name:          migration.Name(),
+		}); err != nil {
+			return fmt.Errorf("failed to record migration progress: %w", err)
+		}
+
+		return nil
+	})
+
+	//nolint:wrapcheck
+	return progress, err
+}
+
+// schemaHash returns the hash of the live schema to record with migrations
+// marked as applied, or "" when m records no schema hashes.
+func (m *Migrator) schemaHash(ctx context.Context, conn *pgx.Conn) (string, error) {
+	if m.skipSchemaHash {
+		return "", nil
+	}
+
+	return computeSchemaHash(ctx, conn)
+}
+
 func computeSchemaHash(ctx context.Context, conn *pgx.Conn) (string, error) {
+	ctx, span := tracing.FromContext(ctx).Start(ctx, "conduit.schema_hash")
+
 	db := stdlib.OpenDB(*conn.Config())
 	defer db.Close()
 
 	hash, err := schema.GetSchemaHash(ctx, db)
 	if err != nil {
-		return "", fmt.Errorf("failed to compute schema hash: %w", err)
+		err = fmt.Errorf("failed to compute schema hash: %w", err)
+		tracing.End(span, err)
+
+		return "", err
 	}
 
+	span.SetAttributes(attrSchemaHash.String(hash))
+	tracing.End(span, nil)
+
 	return hash, nil
 }
@@ -3,13 +3,25 @@ package conduit
 import (
 	"io/fs"
 
+	"go.inout.gg/foundations/must"
+
 	"go.inout.gg/conduit/conduitregistry"
 )
 
 //nolint:gochecknoglobals
 var globalRegistry = conduitregistry.New()
 
-// FromFS registers SQL migrations from the provided filesystem in the global registry.
-func FromFS(fs fs.FS, root string) {
-	globalRegistry = conduitregistry.FromIOFS(fs, root)
+// FromFS registers SQL migrations from the provided filesystem in the global
+// registry. Calling it several times, e.g. once per namespace, adds to the
+// registry rather than replacing it. It panics if parsing fails or if a
+// migration is already registered.
+//
+// Dependencies on migrations of other namespaces are resolved when a
+// [Migrator] orders the migrations, rather than here, as the calls may run
+// in any order, e.g. from the init functions of several packages.
+func FromFS(fs fs.FS, root string, opts ...conduitregistry.Option) {
+	globalRegistry = must.Must(conduitregistry.Merge(
+		globalRegistry,
+		conduitregistry.FromIOFS(fs, root, opts...),
+	))
 }
@@ -0,0 +1,258 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/internaldebug"
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/pkg/conduitbuildinfo"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+var ErrNoMigrationLog = errors.New("migration log table does not exist")
+
+// HistoryOutcome is how a logged migration run ended.
+type HistoryOutcome string
+
+const (
+	// HistoryOutcomeSucceeded marks a migration the executor ran
+	// successfully.
+	HistoryOutcomeSucceeded HistoryOutcome = "succeeded"
+
+	// HistoryOutcomeFailed marks a migration the executor failed to run.
+	HistoryOutcomeFailed HistoryOutcome = "failed"
+
+	// HistoryOutcomeMarked marks a migration recorded without running it,
+	// by [Migrator.MarkApplied], [Migrator.Baseline], [Migrator.MarkUnapplied]
+	// or [Migrator.Resolve].
+	HistoryOutcomeMarked HistoryOutcome = "marked"
+)
+
+// HistoryEntry is a single entry of the migration log.
+//
+// Unlike the history table, which holds the migrations currently applied,
+// the log is append-only: every run of a migration in either direction,
+// successful or not, adds an entry. DatabaseUser and ApplicationName are
+// those of the session the migration ran on, and Hostname and
+// ConduitVersion those of the process that ran it.
+type HistoryEntry struct {
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	Version         conduitversion.Version
+	Namespace       string
+	Name            string
+	Direction       Direction
+	Outcome         HistoryOutcome
+	Error           string
+	DatabaseUser    string
+	ApplicationName string
+	Hostname        string
+	ConduitVersion  string
+	AllowedHazards  []HazardType
+	Duration        time.Duration
+}
+
+// Key returns the migration key in the same format as [Migration.Key].
+func (e *HistoryEntry) Key() string {
+	return conduitregistry.Key(e.Namespace, e.Version.String(), e.Name)
+}
+
+// History returns the latest limit entries of the migration log, newest
+// first; a limit of 0 returns every entry.
+//
+// The log is kept in a table alongside the history table, named after it
+// with a "_log" suffix. Databases initialised before the log existed get it
+// the first time they are migrated, along with the rest of the history table
+// upgrade; until then, History returns [ErrNoMigrationLog].
+func (m *Migrator) History(ctx context.Context, db DB, limit int) ([]*HistoryEntry, error) {
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	log := m.table.Log()
+
+	ok, err := dbsqlc.New().DoesTableExist(ctx, s.conn, log.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from migration log table: %w", err)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoMigrationLog, log)
+	}
+
+	rows, err := dbsqlc.New().MigrationLog(
+		ctx,
+		dbsqlc.WithTable(s.conn, m.table),
+		int32(limit), //nolint:gosec
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch migration log: %w", err)
+	}
+
+	entries := make([]*HistoryEntry, 0, len(rows))
+
+	for _, row := range rows {
+		version, err := conduitversion.Parse(row.Version)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to parse logged migration %s: %w",
+				conduitregistry.Key(row.Namespace, row.Version, row.Name),
+				err,
+			)
+		}
+
+		entries = append(entries, &HistoryEntry{
+			StartedAt:       row.StartedAt.Time,
+			FinishedAt:      row.FinishedAt.Time,
+			Version:         version,
+			Namespace:       row.Namespace,
+			Name:            row.Name,
+			Direction:       Direction(row.Direction),
+			Outcome:         HistoryOutcome(row.Outcome),
+			Error:           row.Error,
+			DatabaseUser:    row.DbUser,
+			ApplicationName: row.ApplicationName,
+			Hostname:        row.Hostname,
+			ConduitVersion:  row.ConduitVersion,
+			AllowedHazards:  row.AllowedHazards,
+			Duration:        time.Duration(row.DurationMs) * time.Millisecond,
+		})
+	}
+
+	return entries, nil
+}
+
+// logEntry is a migration log entry about to be written.
+type logEntry struct {
+	started        time.Time
+	finished       time.Time
+	err            error
+	namespace      string
+	version        string
+	name           string
+	dir            Direction
+	outcome        HistoryOutcome
+	allowedHazards []HazardType
+}
+
+// newLogEntry returns the entry of migration run in direction dir from
+// started until now, failed with err, if any.
+func newLogEntry(
+	ctx context.Context,
+	migration *Migration,
+	dir Direction,
+	started time.Time,
+	err error,
+) logEntry {
+	outcome := HistoryOutcomeSucceeded
+	if err != nil {
+		outcome = HistoryOutcomeFailed
+	}
+
+	return logEntry{
+		started:        started,
+		finished:       time.Now(),
+		err:            err,
+		namespace:      migration.Namespace(),
+		version:        migration.Version().String(),
+		name:           migration.Name(),
+		dir:            dir,
+		outcome:        outcome,
+		allowedHazards: allowedHazards(ctx),
+	}
+}
+
+// newMarkedLogEntry returns the entry of a migration recorded in direction
+// dir without running it.
+func newMarkedLogEntry(s *MigrationStatus, dir Direction) logEntry {
+	now := time.Now()
+
+	return logEntry{
+		started:        now,
+		finished:       now,
+		err:            nil,
+		namespace:      s.Namespace,
+		version:        s.Version.String(),
+		name:           s.Name,
+		dir:            dir,
+		outcome:        HistoryOutcomeMarked,
+		allowedHazards: nil,
+	}
+}
+
+// writeLog appends e to the log table kept alongside table. Nothing is
+// written when the log table does not exist.
+func writeLog(ctx context.Context, db dbsqlc.DBTX, table migrations.Table, e logEntry) error {
+	log := table.Log()
+
+	ok, err := dbsqlc.New().DoesTableExist(ctx, db, log.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch from migration log table: %w", err)
+	}
+
+	if !ok {
+		internaldebug.Log("%s table is not found, skipping migration log", log)
+		return nil
+	}
+
+	var errText string
+	if e.err != nil {
+		errText = e.err.Error()
+	}
+
+	allowed := e.allowedHazards
+	if allowed == nil {
+		allowed = []HazardType{} // allowed_hazards is NOT NULL
+	}
+
+	err = dbsqlc.New().LogMigration(ctx, dbsqlc.WithTable(db, table), dbsqlc.LogMigrationParams{
+		Namespace:      e.namespace,
+		Version:        e.version,
+		Name:           e.name,
+		Direction:      string(e.dir),
+		Outcome:        string(e.outcome),
+		StartedAt:      pgtype.Timestamptz{Time: e.started, Valid: true},  //nolint:exhaustruct
+		FinishedAt:     pgtype.Timestamptz{Time: e.finished, Valid: true}, //nolint:exhaustruct
+		DurationMs:     e.finished.Sub(e.started).Milliseconds(),
+		Error:          errText,
+		Hostname:       hostname(),
+		ConduitVersion: conduitbuildinfo.Standard{}.Version(),
+		AllowedHazards: allowed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write migration log: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:gochecknoglobals
+var hostname = sync.OnceValue(func() string {
+	h, _ := os.Hostname()
+	return h
+})
+
+type allowedHazardsKey struct{}
+
+// withAllowedHazards returns ctx carrying the hazard types a migration run
+// allows, so that the executor can log them.
+func withAllowedHazards(ctx context.Context, allow []HazardType) context.Context {
+	return context.WithValue(ctx, allowedHazardsKey{}, allow)
+}
+
+// allowedHazards returns the hazard types the migration run allows.
+func allowedHazards(ctx context.Context) []HazardType {
+	allow, _ := ctx.Value(allowedHazardsKey{}).([]HazardType)
+	return allow
+}
@@ -0,0 +1,136 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_History(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply migration, when its run cannot be logged", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		testutil.Exec(t, pool, string(migrations.SchemaFor(migrations.NewTable("audit", ""))))
+		testutil.Exec(t, pool, "ALTER TABLE audit.conduit_migrations_log ADD CONSTRAINT reject CHECK (false) NOT VALID;")
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+			})),
+			conduit.WithHistorySchema("audit"),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.True(t, testutil.TableExists(t, pool, "a"))
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 1)
+	})
+
+	t.Run("should log every run newest first, when migrations are applied and rolled back", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_a.up.sql":   "CREATE TABLE a (id INT);",
+				"20230601120000_create_a.down.sql": "DROP TABLE a;",
+			})),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		for _, dir := range []conduit.Direction{conduit.DirectionUp, conduit.DirectionDown} {
+			seq, err := m.Migrate(t.Context(), dir, conn, &conduit.MigrateOptions{
+				AllowHazards: []conduit.HazardType{conduit.HazardTypeDeletesData},
+			})
+			require.NoError(t, err)
+			testutil.CollectSeq2(t, seq)
+		}
+
+		// Act
+		entries, err := m.History(t.Context(), conn, 0)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, conduit.DirectionDown, entries[0].Direction)
+		assert.Equal(t, conduit.DirectionUp, entries[1].Direction)
+
+		for _, e := range entries {
+			assert.Equal(t, "20230601120000_create_a", e.Key())
+			assert.Equal(t, conduit.HistoryOutcomeSucceeded, e.Outcome)
+			assert.Equal(t, []conduit.HazardType{conduit.HazardTypeDeletesData}, e.AllowedHazards)
+			assert.NotEmpty(t, e.DatabaseUser)
+			assert.False(t, e.FinishedAt.Before(e.StartedAt))
+		}
+	})
+
+	t.Run("should log the error, when a migration fails", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_fail.up.sql": "SELECT 1/0;",
+		})))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+
+		for _, err := range seq {
+			if err != nil {
+				break
+			}
+		}
+
+		// Act
+		entries, err := m.History(t.Context(), conn, 1)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, conduit.HistoryOutcomeFailed, entries[0].Outcome)
+		assert.Contains(t, entries[0].Error, "division by zero")
+	})
+
+	t.Run("should log marked migrations", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		})))
+
+		_, err := m.MarkApplied(t.Context(), conn, parseVersion(t, "20230601120000"))
+		require.NoError(t, err)
+
+		// Act
+		entries, err := m.History(t.Context(), conn, 0)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, conduit.HistoryOutcomeMarked, entries[0].Outcome)
+		assert.Equal(t, conduit.DirectionUp, entries[0].Direction)
+	})
+}
@@ -0,0 +1,140 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit/conduitregistry"
+)
+
+var ErrHookFailed = errors.New("migration hook failed")
+
+// Hooks are callbacks the Migrator runs around a migration run, each
+// migration and each statement. Every hook is optional.
+//
+// Before hooks abort on error: a failing BeforeRun runs no migration, a
+// failing BeforeMigration does not start the migration, and a failing
+// BeforeStatement skips the statement and fails the migration as a failed
+// statement would. After hooks receive the error the run, migration or
+// statement failed with, if any, and are only called when the matching
+// before hook succeeded. An error returned by any hook fails the run and
+// matches [ErrHookFailed].
+//
+// conn is the session migrations run on. Statement hooks of a migration
+// that runs in a transaction are called inside that transaction, and are
+// never called for Go migrations, which have no statements.
+//
+// [Migrator.Redo] is two runs, the rollback then the reapply, and
+// [Migrator.Resume] is a run of the single resumed migration.
+type Hooks struct {
+	BeforeRun func(ctx context.Context, conn *pgx.Conn, dir Direction) error
+	AfterRun  func(ctx context.Context, conn *pgx.Conn, dir Direction, err error) error
+
+	BeforeMigration func(ctx context.Context, conn *pgx.Conn, migration *Migration, dir Direction) error
+	AfterMigration  func(
+		ctx context.Context,
+		conn *pgx.Conn,
+		migration *Migration,
+		dir Direction,
+		err error,
+	) error
+
+	BeforeStatement func(
+		ctx context.Context,
+		conn *pgx.Conn,
+		migration *Migration,
+		dir Direction,
+		stmt string,
+	) error
+	AfterStatement func(
+		ctx context.Context,
+		conn *pgx.Conn,
+		migration *Migration,
+		dir Direction,
+		stmt string,
+		err error,
+	) error
+}
+
+func (h Hooks) beforeRun(ctx context.Context, conn *pgx.Conn, dir Direction) error {
+	if h.BeforeRun == nil {
+		return nil
+	}
+
+	return hookError("before run", h.BeforeRun(ctx, conn, dir))
+}
+
+func (h Hooks) afterRun(ctx context.Context, conn *pgx.Conn, dir Direction, err error) error {
+	if h.AfterRun == nil {
+		return nil
+	}
+
+	return hookError("after run", h.AfterRun(ctx, conn, dir, err))
+}
+
+// execute runs a single migration with run, calling the migration hooks
+// around it and handing run a context that carries the statement hooks.
+func (h Hooks) execute(
+	ctx context.Context,
+	conn *pgx.Conn,
+	migration *Migration,
+	dir Direction,
+	run func(context.Context) (MigrationResult, error),
+) (MigrationResult, error) {
+	if h.BeforeMigration != nil {
+		if err := h.BeforeMigration(ctx, conn, migration, dir); err != nil {
+			return MigrationResult{}, hookError("before migration "+migration.Key(), err)
+		}
+	}
+
+	result, err := run(h.statementContext(ctx, conn, migration, dir))
+
+	if h.AfterMigration != nil {
+		if hookErr := h.AfterMigration(ctx, conn, migration, dir, err); hookErr != nil {
+			return MigrationResult{}, errors.Join(err, hookError("after migration "+migration.Key(), hookErr))
+		}
+	}
+
+	return result, err
+}
+
+// statementContext returns ctx carrying the statement hooks bound to
+// migration, if any are set.
+func (h Hooks) statementContext(
+	ctx context.Context,
+	conn *pgx.Conn,
+	migration *Migration,
+	dir Direction,
+) context.Context {
+	if h.BeforeStatement == nil && h.AfterStatement == nil {
+		return ctx
+	}
+
+	var hooks conduitregistry.StatementHooks
+
+	if h.BeforeStatement != nil {
+		hooks.Before = func(ctx context.Context, stmt string) error {
+			return hookError("before statement", h.BeforeStatement(ctx, conn, migration, dir, stmt))
+		}
+	}
+
+	if h.AfterStatement != nil {
+		hooks.After = func(ctx context.Context, stmt string, err error) error {
+			return hookError("after statement", h.AfterStatement(ctx, conn, migration, dir, stmt, err))
+		}
+	}
+
+	return conduitregistry.WithStatementHooks(ctx, hooks)
+}
+
+// hookError wraps err returned by the hook at point in [ErrHookFailed].
+func hookError(point string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s: %w", ErrHookFailed, point, err)
+}
@@ -0,0 +1,128 @@
+package conduit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Hooks(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);\nCREATE INDEX a_id ON a (id);",
+		"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+	}
+
+	t.Run("should call hooks in order, when migrations are applied", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+
+		var events []string
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithHooks(conduit.Hooks{
+				BeforeRun: func(_ context.Context, _ *pgx.Conn, dir conduit.Direction) error {
+					events = append(events, "before run "+string(dir))
+					return nil
+				},
+				AfterRun: func(_ context.Context, _ *pgx.Conn, _ conduit.Direction, err error) error {
+					events = append(events, fmt.Sprintf("after run err=%v", err))
+					return nil
+				},
+				BeforeMigration: func(_ context.Context, _ *pgx.Conn, m *conduit.Migration, _ conduit.Direction) error {
+					events = append(events, "before "+m.Name())
+					return nil
+				},
+				AfterMigration: func(_ context.Context, _ *pgx.Conn, m *conduit.Migration, _ conduit.Direction, _ error) error {
+					events = append(events, "after "+m.Name())
+					return nil
+				},
+				AfterStatement: func(
+					_ context.Context,
+					_ *pgx.Conn,
+					_ *conduit.Migration,
+					_ conduit.Direction,
+					stmt string,
+					_ error,
+				) error {
+					events = append(events, "statement "+stmt)
+					return nil
+				},
+			}),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+		assert.Equal(t, []string{
+			"before run up",
+			"before create_a",
+			"statement CREATE TABLE a (id INT);",
+			"statement CREATE INDEX a_id ON a (id);",
+			"after create_a",
+			"before create_b",
+			"statement CREATE TABLE b (id INT);",
+			"after create_b",
+			"after run err=<nil>",
+		}, events)
+	})
+
+	t.Run("should not apply migration, when before migration hook fails", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		errHook := errors.New("hook error")
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithHooks(conduit.Hooks{
+				BeforeMigration: func(_ context.Context, _ *pgx.Conn, m *conduit.Migration, _ conduit.Direction) error {
+					if m.Name() == "create_b" {
+						return errHook
+					}
+
+					return nil
+				},
+			}),
+		)
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+
+		// Act
+		var iterErr error
+
+		for _, err := range seq {
+			if err != nil {
+				iterErr = err
+			}
+		}
+
+		// Assert
+		require.ErrorIs(t, iterErr, conduit.ErrHookFailed)
+		require.ErrorIs(t, iterErr, errHook)
+		assert.Len(t, appliedMigrations(t, pool), 1)
+		assert.False(t, testutil.TableExists(t, pool, "b"))
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Empty(t, report.Dirty())
+	})
+}
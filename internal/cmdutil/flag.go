@@ -12,6 +12,16 @@ const (
 	MigrationsDir        = "migrations-dir"
 	ExcludeSchemas       = "exclude-schema"
 	SkipSchemaDriftCheck = "skip-schema-drift-check"
+	HistoryTable         = "history-table"
+	HistorySchema        = "history-schema"
+	LockKey              = "lock-key"
+	LockTimeout          = "lock-timeout"
+	OutOfOrder           = "out-of-order"
+	Orphaned             = "orphaned"
+	Tags                 = "tags"
+	ExcludeTags          = "exclude-tags"
+	TenantsQuery         = "tenants-query"
+	TemplateVar          = "template-var"
 )
 
 func MigrationsDirFlag(src altsrc.Sourcer) *cli.StringFlag {
@@ -64,6 +74,80 @@ func SkipSchemaDriftCheckFlag(src altsrc.Sourcer) *cli.BoolFlag {
 	}
 }
 
+func HistoryTableFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name:  HistoryTable,
+		Usage: "table applied migrations are recorded in (default: conduit_migrations)",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_HISTORY_TABLE"),
+			yamlsrc.YAML("history.table", src),
+		),
+	}
+}
+
+func HistorySchemaFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name:  HistorySchema,
+		Usage: "PostgreSQL schema of the history table (default: resolved via search_path)",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_HISTORY_SCHEMA"),
+			yamlsrc.YAML("history.schema", src),
+		),
+	}
+}
+
+func LockKeyFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name:  LockKey,
+		Usage: "identity of the advisory lock held while migrating",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_LOCK_KEY"),
+			yamlsrc.YAML("history.lock-key", src),
+		),
+	}
+}
+
+func LockTimeoutFlag(src altsrc.Sourcer) *cli.DurationFlag {
+	//nolint:exhaustruct
+	return &cli.DurationFlag{
+		Name:  LockTimeout,
+		Usage: "maximum time to wait for the advisory lock held by another session (default: wait indefinitely)",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_LOCK_TIMEOUT"),
+			yamlsrc.YAML("history.lock-timeout", src),
+		),
+	}
+}
+
+func OutOfOrderFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name:  OutOfOrder,
+		Usage: "policy for pending migrations older than the latest applied one: allow, warn or error",
+		Value: "allow",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_OUT_OF_ORDER"),
+			yamlsrc.YAML("history.out-of-order", src),
+		),
+	}
+}
+
+func OrphanedFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name:  Orphaned,
+		Usage: "policy for applied migrations missing from the migrations directory: allow, warn or error",
+		Value: "allow",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_ORPHANED"),
+			yamlsrc.YAML("history.orphaned", src),
+		),
+	}
+}
+
 func VerboseFlag(src altsrc.Sourcer) *cli.BoolFlag {
 	//nolint:exhaustruct
 	return &cli.BoolFlag{
@@ -75,3 +159,52 @@ func VerboseFlag(src altsrc.Sourcer) *cli.BoolFlag {
 		),
 	}
 }
+
+func TagsFlag(src altsrc.Sourcer) *cli.StringSliceFlag {
+	//nolint:exhaustruct
+	return &cli.StringSliceFlag{
+		Name:  Tags,
+		Usage: "tags of the tagged migrations to apply (e.g. dev,seed); untagged migrations always apply",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_TAGS"),
+			yamlsrc.YAML("migrations.tags", src),
+		),
+	}
+}
+
+func ExcludeTagsFlag(src altsrc.Sourcer) *cli.StringSliceFlag {
+	//nolint:exhaustruct
+	return &cli.StringSliceFlag{
+		Name:  ExcludeTags,
+		Usage: "tags of the tagged migrations to skip even when another of their tags is selected",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_EXCLUDE_TAGS"),
+			yamlsrc.YAML("migrations.exclude-tags", src),
+		),
+	}
+}
+
+func TenantsQueryFlag(src altsrc.Sourcer) *cli.StringFlag {
+	//nolint:exhaustruct
+	return &cli.StringFlag{
+		Name: TenantsQuery,
+		Usage: "query returning the schemas of the tenants to migrate, one per row " +
+			"(e.g. SELECT schema_name FROM tenants); each schema keeps its own history table",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_TENANTS_QUERY"),
+			yamlsrc.YAML("tenants.query", src),
+		),
+	}
+}
+
+func TemplateVarFlag(src altsrc.Sourcer) *cli.StringMapFlag {
+	//nolint:exhaustruct
+	return &cli.StringMapFlag{
+		Name:  TemplateVar,
+		Usage: "variable templated migrations are rendered with, as KEY=VALUE (e.g. schema=app); may be repeated",
+		Sources: cli.NewValueSourceChain(
+			cli.EnvVar("CONDUIT_TEMPLATE_VARS"),
+			yamlsrc.YAML("migrations.template-vars", src),
+		),
+	}
+}
@@ -0,0 +1,36 @@
+package cmdutil
+
+import (
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/urfave/cli/v3"
+
+	"go.inout.gg/conduit/conduitregistry"
+)
+
+// Registry loads the migrations of the directory set with
+// [MigrationsDirFlag], rendering templated migrations with the variables
+// set with [TemplateVarFlag].
+func Registry(fs afero.Fs, cmd *cli.Command) (*conduitregistry.Registry, error) {
+	r, err := conduitregistry.Load(
+		fs,
+		cmd.String(MigrationsDir),
+		conduitregistry.WithTemplateVars(TemplateVars(cmd)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return r, nil
+}
+
+// TemplateVars returns the variables set with [TemplateVarFlag].
+func TemplateVars(cmd *cli.Command) map[string]any {
+	vars := make(map[string]any)
+	for k, v := range cmd.StringMap(TemplateVar) {
+		vars[k] = v
+	}
+
+	return vars
+}
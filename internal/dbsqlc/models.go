@@ -9,9 +9,31 @@ import (
 )
 
 type ConduitMigration struct {
-	ID        int64
-	CreatedAt pgtype.Timestamp
-	Version   string
-	Name      string
-	Hash      string
+	ID             int64
+	CreatedAt      pgtype.Timestamp
+	Namespace      string
+	Version        string
+	Name           string
+	Hash           string
+	Checksum       string
+	DirtyDirection string
+	LastStatement  int32
+}
+
+type ConduitMigrationsLog struct {
+	ID              int64
+	Namespace       string
+	Version         string
+	Name            string
+	Direction       string
+	Outcome         string
+	StartedAt       pgtype.Timestamptz
+	FinishedAt      pgtype.Timestamptz
+	DurationMs      int64
+	Error           string
+	DbUser          string
+	ApplicationName string
+	Hostname        string
+	ConduitVersion  string
+	AllowedHazards  []string
 }
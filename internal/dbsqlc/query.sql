@@ -1,30 +1,142 @@
 -- name: AcquireLock :exec
 SELECT pg_advisory_lock(@lock_num::BIGINT);
 
+-- name: AcquireXactLock :exec
+SELECT pg_advisory_xact_lock(@lock_num::BIGINT);
+
+-- name: TryAcquireXactLock :one
+SELECT pg_try_advisory_xact_lock(@lock_num::BIGINT);
+
+-- name: TryAcquireLock :one
+SELECT pg_try_advisory_lock(@lock_num::BIGINT);
+
+-- name: LockSessions :many
+SELECT
+  a.pid,
+  COALESCE(a.usename::TEXT, '')::TEXT AS usename,
+  a.application_name,
+  COALESCE(a.state, '')::TEXT AS state,
+  a.query,
+  a.query_start,
+  l.granted
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory'
+  AND l.objsubid = 1
+  AND ((l.classid::BIGINT << 32) | l.objid::BIGINT) = @lock_num::BIGINT
+ORDER BY l.granted DESC, a.query_start;
+
 -- name: ReleaseLock :exec
 SELECT pg_advisory_unlock(@lock_num::BIGINT);
 
 -- name: ResetConn :exec
 RESET ALL;
 
+-- name: SetConfig :exec
+SELECT set_config(@name::TEXT, @value::TEXT, @is_local::BOOLEAN);
+
 -- name: AllExistingMigrations :many
-SELECT version, name
+SELECT namespace, version, name
 FROM conduit_migrations
-ORDER BY version, name;
+ORDER BY version, namespace, name;
+
+-- name: AllMigrationRecords :many
+SELECT namespace, version, name, hash, checksum, dirty_direction, last_statement, created_at
+FROM conduit_migrations
+ORDER BY version, namespace, name;
 
 -- name: ApplyMigration :exec
-INSERT INTO conduit_migrations (version, name, hash)
-VALUES (@version, @name, @hash);
+INSERT INTO conduit_migrations (namespace, version, name, hash, checksum)
+VALUES (@namespace, @version, @name, @hash, @checksum)
+ON CONFLICT (namespace, version, name) DO UPDATE
+SET hash = EXCLUDED.hash,
+  checksum = EXCLUDED.checksum,
+  dirty_direction = '',
+  last_statement = 0,
+  created_at = CURRENT_TIMESTAMP;
+
+-- name: MarkMigrationDirty :exec
+INSERT INTO conduit_migrations (namespace, version, name, hash, checksum, dirty_direction)
+VALUES (@namespace, @version, @name, '', @checksum, @dirty_direction)
+ON CONFLICT (namespace, version, name) DO UPDATE
+SET dirty_direction = EXCLUDED.dirty_direction,
+  last_statement = 0;
+
+-- name: UpdateMigrationProgress :exec
+UPDATE conduit_migrations
+SET last_statement = @last_statement
+WHERE namespace = @namespace AND version = @version AND name = @name;
 
 -- name: RollbackMigration :exec
 DELETE FROM conduit_migrations
-WHERE version = @version AND name = @name;
+WHERE namespace = @namespace AND version = @version AND name = @name;
+
+-- name: LogMigration :exec
+INSERT INTO conduit_migrations_log (
+  namespace,
+  version,
+  name,
+  direction,
+  outcome,
+  started_at,
+  finished_at,
+  duration_ms,
+  error,
+  hostname,
+  conduit_version,
+  allowed_hazards
+)
+VALUES (
+  @namespace,
+  @version,
+  @name,
+  @direction,
+  @outcome,
+  @started_at,
+  @finished_at,
+  @duration_ms,
+  @error,
+  @hostname,
+  @conduit_version,
+  @allowed_hazards
+);
+
+-- name: MigrationLog :many
+SELECT
+  namespace,
+  version,
+  name,
+  direction,
+  outcome,
+  started_at,
+  finished_at,
+  duration_ms,
+  error,
+  db_user,
+  application_name,
+  hostname,
+  conduit_version,
+  allowed_hazards
+FROM conduit_migrations_log
+ORDER BY id DESC
+LIMIT NULLIF(@row_limit::INT, 0);
+
+-- name: UpdateMigrationChecksum :exec
+UPDATE conduit_migrations
+SET checksum = @checksum
+WHERE namespace = @namespace AND version = @version AND name = @name;
 
 -- name: LatestSchemaHash :one
 SELECT hash FROM conduit_migrations
-ORDER BY version DESC, name DESC
+WHERE dirty_direction = ''
+ORDER BY id DESC
 LIMIT 1;
 
+-- name: ReplaceSchemaHash :exec
+UPDATE conduit_migrations
+SET hash = @new_hash
+WHERE hash = @old_hash;
+
 -- name: TestAllMigrations :many
 SELECT version, name
 FROM conduit_migrations
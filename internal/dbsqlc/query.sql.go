@@ -7,6 +7,8 @@ package dbsqlc
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const acquireLock = `-- name: AcquireLock :exec
@@ -18,15 +20,25 @@ func (q *Queries) AcquireLock(ctx context.Context, db DBTX, lockNum int64) error
 	return err
 }
 
+const acquireXactLock = `-- name: AcquireXactLock :exec
+SELECT pg_advisory_xact_lock($1::BIGINT)
+`
+
+func (q *Queries) AcquireXactLock(ctx context.Context, db DBTX, lockNum int64) error {
+	_, err := db.Exec(ctx, acquireXactLock, lockNum)
+	return err
+}
+
 const allExistingMigrations = `-- name: AllExistingMigrations :many
-SELECT version, name
+SELECT namespace, version, name
 FROM conduit_migrations
-ORDER BY version, name
+ORDER BY version, namespace, name
 `
 
 type AllExistingMigrationsRow struct {
-	Version string
-	Name    string
+	Namespace string
+	Version   string
+	Name      string
 }
 
 func (q *Queries) AllExistingMigrations(ctx context.Context, db DBTX) ([]AllExistingMigrationsRow, error) {
@@ -38,7 +50,53 @@ func (q *Queries) AllExistingMigrations(ctx context.Context, db DBTX) ([]AllExis
 	var items []AllExistingMigrationsRow
 	for rows.Next() {
 		var i AllExistingMigrationsRow
-		if err := rows.Scan(&i.Version, &i.Name); err != nil {
+		if err := rows.Scan(&i.Namespace, &i.Version, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const allMigrationRecords = `-- name: AllMigrationRecords :many
+SELECT namespace, version, name, hash, checksum, dirty_direction, last_statement, created_at
+FROM conduit_migrations
+ORDER BY version, namespace, name
+`
+
+type AllMigrationRecordsRow struct {
+	Namespace      string
+	Version        string
+	Name           string
+	Hash           string
+	Checksum       string
+	DirtyDirection string
+	LastStatement  int32
+	CreatedAt      pgtype.Timestamp
+}
+
+func (q *Queries) AllMigrationRecords(ctx context.Context, db DBTX) ([]AllMigrationRecordsRow, error) {
+	rows, err := db.Query(ctx, allMigrationRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AllMigrationRecordsRow
+	for rows.Next() {
+		var i AllMigrationRecordsRow
+		if err := rows.Scan(
+			&i.Namespace,
+			&i.Version,
+			&i.Name,
+			&i.Hash,
+			&i.Checksum,
+			&i.DirtyDirection,
+			&i.LastStatement,
+			&i.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -50,18 +108,32 @@ func (q *Queries) AllExistingMigrations(ctx context.Context, db DBTX) ([]AllExis
 }
 
 const applyMigration = `-- name: ApplyMigration :exec
-INSERT INTO conduit_migrations (version, name, hash)
-VALUES ($1, $2, $3)
+INSERT INTO conduit_migrations (namespace, version, name, hash, checksum)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (namespace, version, name) DO UPDATE
+SET hash = EXCLUDED.hash,
+  checksum = EXCLUDED.checksum,
+  dirty_direction = '',
+  last_statement = 0,
+  created_at = CURRENT_TIMESTAMP
 `
 
 type ApplyMigrationParams struct {
-	Version string
-	Name    string
-	Hash    string
+	Namespace string
+	Version   string
+	Name      string
+	Hash      string
+	Checksum  string
 }
 
 func (q *Queries) ApplyMigration(ctx context.Context, db DBTX, arg ApplyMigrationParams) error {
-	_, err := db.Exec(ctx, applyMigration, arg.Version, arg.Name, arg.Hash)
+	_, err := db.Exec(ctx, applyMigration,
+		arg.Namespace,
+		arg.Version,
+		arg.Name,
+		arg.Hash,
+		arg.Checksum,
+	)
 	return err
 }
 
@@ -82,7 +154,8 @@ func (q *Queries) DoesTableExist(ctx context.Context, db DBTX, tableName string)
 
 const latestSchemaHash = `-- name: LatestSchemaHash :one
 SELECT hash FROM conduit_migrations
-ORDER BY version DESC, name DESC
+WHERE dirty_direction = ''
+ORDER BY id DESC
 LIMIT 1
 `
 
@@ -93,6 +166,225 @@ func (q *Queries) LatestSchemaHash(ctx context.Context, db DBTX) (string, error)
 	return hash, err
 }
 
+const lockSessions = `-- name: LockSessions :many
+SELECT
+  a.pid,
+  COALESCE(a.usename::TEXT, '')::TEXT AS usename,
+  a.application_name,
+  COALESCE(a.state, '')::TEXT AS state,
+  a.query,
+  a.query_start,
+  l.granted
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory'
+  AND l.objsubid = 1
+  AND ((l.classid::BIGINT << 32) | l.objid::BIGINT) = $1::BIGINT
+ORDER BY l.granted DESC, a.query_start
+`
+
+type LockSessionsRow struct {
+	Pid             pgtype.Int4
+	Usename         string
+	ApplicationName pgtype.Text
+	State           string
+	Query           pgtype.Text
+	QueryStart      pgtype.Timestamptz
+	Granted         pgtype.Bool
+}
+
+func (q *Queries) LockSessions(ctx context.Context, db DBTX, lockNum int64) ([]LockSessionsRow, error) {
+	rows, err := db.Query(ctx, lockSessions, lockNum)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LockSessionsRow
+	for rows.Next() {
+		var i LockSessionsRow
+		if err := rows.Scan(
+			&i.Pid,
+			&i.Usename,
+			&i.ApplicationName,
+			&i.State,
+			&i.Query,
+			&i.QueryStart,
+			&i.Granted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const logMigration = `-- name: LogMigration :exec
+INSERT INTO conduit_migrations_log (
+  namespace,
+  version,
+  name,
+  direction,
+  outcome,
+  started_at,
+  finished_at,
+  duration_ms,
+  error,
+  hostname,
+  conduit_version,
+  allowed_hazards
+)
+VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11,
+  $12
+)
+`
+
+type LogMigrationParams struct {
+	Namespace      string
+	Version        string
+	Name           string
+	Direction      string
+	Outcome        string
+	StartedAt      pgtype.Timestamptz
+	FinishedAt     pgtype.Timestamptz
+	DurationMs     int64
+	Error          string
+	Hostname       string
+	ConduitVersion string
+	AllowedHazards []string
+}
+
+func (q *Queries) LogMigration(ctx context.Context, db DBTX, arg LogMigrationParams) error {
+	_, err := db.Exec(ctx, logMigration,
+		arg.Namespace,
+		arg.Version,
+		arg.Name,
+		arg.Direction,
+		arg.Outcome,
+		arg.StartedAt,
+		arg.FinishedAt,
+		arg.DurationMs,
+		arg.Error,
+		arg.Hostname,
+		arg.ConduitVersion,
+		arg.AllowedHazards,
+	)
+	return err
+}
+
+const markMigrationDirty = `-- name: MarkMigrationDirty :exec
+INSERT INTO conduit_migrations (namespace, version, name, hash, checksum, dirty_direction)
+VALUES ($1, $2, $3, '', $4, $5)
+ON CONFLICT (namespace, version, name) DO UPDATE
+SET dirty_direction = EXCLUDED.dirty_direction,
+  last_statement = 0
+`
+
+type MarkMigrationDirtyParams struct {
+	Namespace      string
+	Version        string
+	Name           string
+	Checksum       string
+	DirtyDirection string
+}
+
+func (q *Queries) MarkMigrationDirty(ctx context.Context, db DBTX, arg MarkMigrationDirtyParams) error {
+	_, err := db.Exec(ctx, markMigrationDirty,
+		arg.Namespace,
+		arg.Version,
+		arg.Name,
+		arg.Checksum,
+		arg.DirtyDirection,
+	)
+	return err
+}
+
+const migrationLog = `-- name: MigrationLog :many
+SELECT
+  namespace,
+  version,
+  name,
+  direction,
+  outcome,
+  started_at,
+  finished_at,
+  duration_ms,
+  error,
+  db_user,
+  application_name,
+  hostname,
+  conduit_version,
+  allowed_hazards
+FROM conduit_migrations_log
+ORDER BY id DESC
+LIMIT NULLIF($1::INT, 0)
+`
+
+type MigrationLogRow struct {
+	Namespace       string
+	Version         string
+	Name            string
+	Direction       string
+	Outcome         string
+	StartedAt       pgtype.Timestamptz
+	FinishedAt      pgtype.Timestamptz
+	DurationMs      int64
+	Error           string
+	DbUser          string
+	ApplicationName string
+	Hostname        string
+	ConduitVersion  string
+	AllowedHazards  []string
+}
+
+func (q *Queries) MigrationLog(ctx context.Context, db DBTX, rowLimit int32) ([]MigrationLogRow, error) {
+	rows, err := db.Query(ctx, migrationLog, rowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MigrationLogRow
+	for rows.Next() {
+		var i MigrationLogRow
+		if err := rows.Scan(
+			&i.Namespace,
+			&i.Version,
+			&i.Name,
+			&i.Direction,
+			&i.Outcome,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.DurationMs,
+			&i.Error,
+			&i.DbUser,
+			&i.ApplicationName,
+			&i.Hostname,
+			&i.ConduitVersion,
+			&i.AllowedHazards,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const releaseLock = `-- name: ReleaseLock :exec
 SELECT pg_advisory_unlock($1::BIGINT)
 `
@@ -102,6 +394,22 @@ func (q *Queries) ReleaseLock(ctx context.Context, db DBTX, lockNum int64) error
 	return err
 }
 
+const replaceSchemaHash = `-- name: ReplaceSchemaHash :exec
+UPDATE conduit_migrations
+SET hash = $1
+WHERE hash = $2
+`
+
+type ReplaceSchemaHashParams struct {
+	NewHash string
+	OldHash string
+}
+
+func (q *Queries) ReplaceSchemaHash(ctx context.Context, db DBTX, arg ReplaceSchemaHashParams) error {
+	_, err := db.Exec(ctx, replaceSchemaHash, arg.NewHash, arg.OldHash)
+	return err
+}
+
 const resetConn = `-- name: ResetConn :exec
 RESET ALL
 `
@@ -113,16 +421,32 @@ func (q *Queries) ResetConn(ctx context.Context, db DBTX) error {
 
 const rollbackMigration = `-- name: RollbackMigration :exec
 DELETE FROM conduit_migrations
-WHERE version = $1 AND name = $2
+WHERE namespace = $1 AND version = $2 AND name = $3
 `
 
 type RollbackMigrationParams struct {
-	Version string
-	Name    string
+	Namespace string
+	Version   string
+	Name      string
 }
 
 func (q *Queries) RollbackMigration(ctx context.Context, db DBTX, arg RollbackMigrationParams) error {
-	_, err := db.Exec(ctx, rollbackMigration, arg.Version, arg.Name)
+	_, err := db.Exec(ctx, rollbackMigration, arg.Namespace, arg.Version, arg.Name)
+	return err
+}
+
+const setConfig = `-- name: SetConfig :exec
+SELECT set_config($1::TEXT, $2::TEXT, $3::BOOLEAN)
+`
+
+type SetConfigParams struct {
+	Name    string
+	Value   string
+	IsLocal bool
+}
+
+func (q *Queries) SetConfig(ctx context.Context, db DBTX, arg SetConfigParams) error {
+	_, err := db.Exec(ctx, setConfig, arg.Name, arg.Value, arg.IsLocal)
 	return err
 }
 
@@ -156,3 +480,71 @@ func (q *Queries) TestAllMigrations(ctx context.Context, db DBTX) ([]TestAllMigr
 	}
 	return items, nil
 }
+
+const tryAcquireLock = `-- name: TryAcquireLock :one
+SELECT pg_try_advisory_lock($1::BIGINT)
+`
+
+func (q *Queries) TryAcquireLock(ctx context.Context, db DBTX, lockNum int64) (bool, error) {
+	row := db.QueryRow(ctx, tryAcquireLock, lockNum)
+	var pg_try_advisory_lock bool
+	err := row.Scan(&pg_try_advisory_lock)
+	return pg_try_advisory_lock, err
+}
+
+const tryAcquireXactLock = `-- name: TryAcquireXactLock :one
+SELECT pg_try_advisory_xact_lock($1::BIGINT)
+`
+
+func (q *Queries) TryAcquireXactLock(ctx context.Context, db DBTX, lockNum int64) (bool, error) {
+	row := db.QueryRow(ctx, tryAcquireXactLock, lockNum)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
+}
+
+const updateMigrationChecksum = `-- name: UpdateMigrationChecksum :exec
+UPDATE conduit_migrations
+SET checksum = $1
+WHERE namespace = $2 AND version = $3 AND name = $4
+`
+
+type UpdateMigrationChecksumParams struct {
+	Checksum  string
+	Namespace string
+	Version   string
+	Name      string
+}
+
+func (q *Queries) UpdateMigrationChecksum(ctx context.Context, db DBTX, arg UpdateMigrationChecksumParams) error {
+	_, err := db.Exec(ctx, updateMigrationChecksum,
+		arg.Checksum,
+		arg.Namespace,
+		arg.Version,
+		arg.Name,
+	)
+	return err
+}
+
+const updateMigrationProgress = `-- name: UpdateMigrationProgress :exec
+UPDATE conduit_migrations
+SET last_statement = $1
+WHERE namespace = $2 AND version = $3 AND name = $4
+`
+
+type UpdateMigrationProgressParams struct {
+	LastStatement int32
+	Namespace     string
+	Version       string
+	Name          string
+}
+
+func (q *Queries) UpdateMigrationProgress(ctx context.Context, db DBTX, arg UpdateMigrationProgressParams) error {
+	_, err := db.Exec(ctx, updateMigrationProgress,
+		arg.LastStatement,
+		arg.Namespace,
+		arg.Version,
+		arg.Name,
+	)
+	return err
+}
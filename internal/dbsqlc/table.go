@@ -0,0 +1,38 @@
+package dbsqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go.inout.gg/conduit/internal/migrations"
+)
+
+var _ DBTX = (*tableDB)(nil)
+
+// WithTable returns a DBTX that runs the generated queries against table and
+// its migration log instead of conduit_migrations and conduit_migrations_log,
+// see [migrations.Table.Rewrite].
+func WithTable(db DBTX, table migrations.Table) DBTX {
+	return &tableDB{db: db, table: table}
+}
+
+type tableDB struct {
+	db    DBTX
+	table migrations.Table
+}
+
+func (t *tableDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	//nolint:wrapcheck
+	return t.db.Exec(ctx, t.table.Rewrite(sql), args...)
+}
+
+func (t *tableDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	//nolint:wrapcheck
+	return t.db.Query(ctx, t.table.Rewrite(sql), args...)
+}
+
+func (t *tableDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.db.QueryRow(ctx, t.table.Rewrite(sql), args...)
+}
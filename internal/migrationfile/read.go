@@ -9,13 +9,21 @@ import (
 
 	"github.com/spf13/afero"
 
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/sqltemplate"
 	"go.inout.gg/conduit/pkg/conduitversion"
 	"go.inout.gg/conduit/pkg/sqlsplit"
 )
 
 // ReadStmtsFromDir reads all up-migration SQL files from dir, ordered by
-// version, and returns the parsed statements.
-func ReadStmtsFromDir(fs afero.Fs, dir string) ([]sqlsplit.Stmt, error) {
+// version with repeatable migrations last, and returns the parsed statements.
+// Templated migrations, see [conduitregistry.TemplateDirective], are
+// rendered with vars first.
+//
+// Tagged migrations, see [conduitregistry.TagsDirectivePrefix], are left out:
+// they run only where their tags are selected, so the objects they create
+// are not part of the schema the migrations describe.
+func ReadStmtsFromDir(fs afero.Fs, dir string, vars map[string]any) ([]sqlsplit.Stmt, error) {
 	entries, err := afero.ReadDir(fs, dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
@@ -50,23 +58,42 @@ func ReadStmtsFromDir(fs afero.Fs, dir string) ([]sqlsplit.Stmt, error) {
 		filename := m.Filename()
 		path := filepath.Join(dir, filename)
 
-		stmts, err := readStmtsFromFile(fs, path)
+		stmts, err := readStmtsFromFile(fs, path, vars)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read migration file %s: %w", path, err)
 		}
 
+		if isTagged(stmts) {
+			continue
+		}
+
 		allStmts = append(allStmts, stmts...)
 	}
 
 	return allStmts, nil
 }
 
-func readStmtsFromFile(fs afero.Fs, path string) ([]sqlsplit.Stmt, error) {
+// isTagged reports whether stmts declare tags.
+func isTagged(stmts []sqlsplit.Stmt) bool {
+	return slices.ContainsFunc(stmts, func(stmt sqlsplit.Stmt) bool {
+		return stmt.Type == sqlsplit.StmtTypeComment &&
+			strings.HasPrefix(strings.TrimSpace(stmt.Content), conduitregistry.TagsDirectivePrefix)
+	})
+}
+
+func readStmtsFromFile(fs afero.Fs, path string, vars map[string]any) ([]sqlsplit.Stmt, error) {
 	content, err := afero.ReadFile(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
+	if sqltemplate.Is(content) {
+		content, err = sqltemplate.Render(filepath.Base(path), content, vars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render: %w", err)
+		}
+	}
+
 	stmts, err := sqlsplit.Split(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse SQL: %w", err)
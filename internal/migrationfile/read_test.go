@@ -24,7 +24,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("20230601130000_second.up.sql", "CREATE TABLE second (id int);").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
@@ -40,7 +40,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile(".gitkeep", "").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
@@ -54,18 +54,61 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("20230601120000_first.up.sql", "CREATE TABLE first (id int);").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
 	})
 
+	t.Run("should skip tagged migrations, when files declare tags", func(t *testing.T) {
+		t.Parallel()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_first.up.sql", "CREATE TABLE first (id int);").
+			WithFile("20230601130000_fixtures.up.sql", "---- tags: dev,seed ----\nINSERT INTO first VALUES (1);").
+			Build()
+
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
+
+		require.NoError(t, err)
+		require.Len(t, stmts, 1)
+		assert.Equal(t, "CREATE TABLE first (id int);", stmts[0].Content)
+	})
+
+	t.Run("should render templated migrations, when files declare template directive", func(t *testing.T) {
+		t.Parallel()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_first.up.sql", "CREATE TABLE first (id int);").
+			WithFile("20230601130000_grants.up.sql", "---- template ----\nGRANT SELECT ON first TO {{ .Role }};").
+			Build()
+
+		stmts, err := ReadStmtsFromDir(fs, dir, map[string]any{"Role": "reporting"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "CREATE TABLE first (id int);", stmts[0].Content)
+		assert.Equal(t, "GRANT SELECT ON first TO reporting;", stmts[len(stmts)-1].Content)
+	})
+
+	t.Run("should return error naming file, when template variable is missing", func(t *testing.T) {
+		t.Parallel()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601130000_grants.up.sql", "---- template ----\nGRANT SELECT ON first TO {{ .Role }};").
+			Build()
+
+		_, err := ReadStmtsFromDir(fs, dir, nil)
+
+		require.ErrorContains(t, err, "20230601130000_grants.up.sql")
+		require.ErrorContains(t, err, "failed to execute template")
+	})
+
 	t.Run("should return error, when directory does not exist", func(t *testing.T) {
 		t.Parallel()
 
 		fs := afero.NewMemMapFs()
 
-		_, err := ReadStmtsFromDir(fs, "/nonexistent")
+		_, err := ReadStmtsFromDir(fs, "/nonexistent", nil)
 
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "failed to read directory")
@@ -79,7 +122,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithReadError("20230601120000_first.up.sql", os.ErrPermission).
 			Build()
 
-		_, err := ReadStmtsFromDir(fs, dir)
+		_, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "failed to read file")
@@ -92,7 +135,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("20230601120000_bad.up.sql", "SELECT 'unclosed string").
 			Build()
 
-		_, err := ReadStmtsFromDir(fs, dir)
+		_, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "unclosed string")
@@ -105,7 +148,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("invalid_filename.sql", "CREATE TABLE test (id int);").
 			Build()
 
-		_, err := ReadStmtsFromDir(fs, dir)
+		_, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.Error(t, err)
 		assert.ErrorContains(t, err, "failed to parse migration filename")
@@ -116,7 +159,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 
 		fs, _, dir := testutil.NewMigrationsDirBuilder(t).Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		assert.Empty(t, stmts)
@@ -129,7 +172,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("README.md", "# Migrations").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		assert.Empty(t, stmts)
@@ -143,7 +186,7 @@ func TestReadStmtsFromDir(t *testing.T) {
 			WithFile("20230601120000_users.down.sql", "DROP TABLE users;").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
@@ -158,7 +201,7 @@ CREATE TABLE posts (id int);
 CREATE INDEX idx_posts ON posts (id);`).
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
@@ -172,7 +215,7 @@ CREATE INDEX idx_posts ON posts (id);`).
 			WithFile("20230601130000_second.up.sql", "CREATE TABLE c (id int);").
 			Build()
 
-		stmts, err := ReadStmtsFromDir(fs, dir)
+		stmts, err := ReadStmtsFromDir(fs, dir, nil)
 
 		require.NoError(t, err)
 		snaps.MatchSnapshot(t, stmts)
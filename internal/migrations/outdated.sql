@@ -0,0 +1,20 @@
+-- Reports whether a history table created by an earlier version of conduit
+-- lacks something upgrade.sql adds.
+SELECT
+  to_regclass('conduit_migrations_log') IS NULL
+  OR (
+    SELECT count(*) FROM pg_attribute
+    WHERE attrelid = 'conduit_migrations'::REGCLASS
+      AND attname IN ('namespace', 'checksum', 'dirty_direction', 'last_statement')
+      AND NOT attisdropped
+  ) < 4
+  OR NOT EXISTS (
+    SELECT 1 FROM pg_constraint c
+    WHERE c.conrelid = 'conduit_migrations'::REGCLASS
+      AND c.contype = 'u'
+      AND ARRAY(
+        SELECT a.attname::TEXT FROM pg_attribute a
+        WHERE a.attrelid = c.conrelid AND a.attnum = ANY (c.conkey)
+        ORDER BY a.attname
+      ) = ARRAY['name', 'namespace', 'version']
+  );
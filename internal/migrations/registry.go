@@ -1,8 +1,128 @@
 package migrations
 
 import (
+	"bytes"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+
 	_ "embed"
 )
 
+// DefaultTable is the name of the history table used when none is
+// configured.
+const DefaultTable = "conduit_migrations"
+
+// LogTableSuffix is appended to the history table name to name the
+// append-only migration log kept alongside it.
+const LogTableSuffix = "_log"
+
 //go:embed schema.sql
 var Schema []byte
+
+//go:embed upgrade.sql
+var upgrade []byte
+
+//go:embed outdated.sql
+var outdated []byte
+
+// Table identifies the history table conduit records applied migrations in.
+// An empty Schema resolves the table through the connection's search_path.
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// NewTable returns the history table with the given schema and name, falling
+// back to [DefaultTable] when name is empty.
+func NewTable(schema, name string) Table {
+	if name == "" {
+		name = DefaultTable
+	}
+
+	return Table{Schema: schema, Name: name}
+}
+
+// IsDefault reports whether t is the unqualified [DefaultTable].
+func (t Table) IsDefault() bool { return t.Schema == "" && t.Name == DefaultTable }
+
+// Log returns the migration log table kept alongside t.
+func (t Table) Log() Table { return Table{Schema: t.Schema, Name: t.Name + LogTableSuffix} }
+
+// String returns the quoted, optionally schema-qualified table identifier.
+func (t Table) String() string {
+	if t.Schema == "" {
+		return pgx.Identifier{t.Name}.Sanitize()
+	}
+
+	return pgx.Identifier{t.Schema, t.Name}.Sanitize()
+}
+
+// SchemaFor returns conduit's internal schema rendered for the given history
+// table. When the table lives in a dedicated schema, the schema is created
+// as well.
+func SchemaFor(t Table) []byte {
+	if t.IsDefault() {
+		return Schema
+	}
+
+	var b bytes.Buffer
+
+	if t.Schema != "" {
+		b.WriteString("CREATE SCHEMA IF NOT EXISTS ")
+		b.WriteString(pgx.Identifier{t.Schema}.Sanitize())
+		b.WriteString(";\n\n")
+	}
+
+	b.WriteString(t.Rewrite(string(Schema)))
+
+	return b.Bytes()
+}
+
+// UpgradeFor returns the statement that brings the given history table, as
+// created by an earlier version of conduit, up to date: it adds the columns
+// and keys added to [Schema] since, and creates the migration log kept
+// alongside it. The table must exist.
+func UpgradeFor(t Table) []byte {
+	if t.IsDefault() {
+		return upgrade
+	}
+
+	return []byte(t.Rewrite(string(upgrade)))
+}
+
+// OutdatedFor returns the query reporting whether the given history table
+// lacks something [UpgradeFor] adds. The table must exist.
+func OutdatedFor(t Table) []byte {
+	if t.IsDefault() {
+		return outdated
+	}
+
+	return []byte(t.Rewrite(string(outdated)))
+}
+
+// identifierRe matches the unquoted identifiers of conduit's internal SQL.
+var identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_$]*`)
+
+// Rewrite renders sql, written against [DefaultTable] and its log, for t.
+// Only identifiers that are exactly conduit_migrations or
+// conduit_migrations_log are rewritten, so that neither name is mistaken for
+// the prefix of another.
+func (t Table) Rewrite(sql string) string {
+	if t.IsDefault() {
+		return sql
+	}
+
+	table, log := t.String(), t.Log().String()
+
+	return identifierRe.ReplaceAllStringFunc(sql, func(ident string) string {
+		switch ident {
+		case DefaultTable:
+			return table
+		case DefaultTable + LogTableSuffix:
+			return log
+		}
+
+		return ident
+	})
+}
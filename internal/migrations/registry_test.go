@@ -0,0 +1,56 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.inout.gg/conduit/internal/migrations"
+)
+
+func TestTable_Rewrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should rewrite history and log tables, when table is custom", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		table := migrations.NewTable("billing", "history")
+
+		// Act
+		sql := table.Rewrite("SELECT * FROM conduit_migrations JOIN conduit_migrations_log USING (name)")
+
+		// Assert
+		assert.Equal(
+			t,
+			`SELECT * FROM "billing"."history" JOIN "billing"."history_log" USING (name)`,
+			sql,
+		)
+	})
+
+	t.Run("should leave other identifiers, when they start with the table name", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		table := migrations.NewTable("", "history")
+
+		// Act
+		sql := table.Rewrite("SELECT conduit_migrations_id_seq, conduit_migrations_logs FROM conduit_migrations")
+
+		// Assert
+		assert.Equal(t, `SELECT conduit_migrations_id_seq, conduit_migrations_logs FROM "history"`, sql)
+	})
+
+	t.Run("should leave sql unchanged, when table is the default", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		table := migrations.NewTable("", "")
+
+		// Act
+		sql := table.Rewrite("SELECT * FROM conduit_migrations_log")
+
+		// Assert
+		assert.Equal(t, "SELECT * FROM conduit_migrations_log", sql)
+	})
+}
@@ -1,9 +1,32 @@
 CREATE TABLE IF NOT EXISTS conduit_migrations (
   id BIGSERIAL NOT NULL,
   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  namespace VARCHAR(255) NOT NULL DEFAULT '',
   version VARCHAR(255) NOT NULL,
   name VARCHAR(4095) NOT NULL,
   hash VARCHAR(64) NOT NULL,
+  checksum VARCHAR(64) NOT NULL DEFAULT '',
+  dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+  last_statement INT NOT NULL DEFAULT 0,
   PRIMARY KEY (id),
-  UNIQUE (version, name)
+  UNIQUE (namespace, version, name)
+);
+
+CREATE TABLE IF NOT EXISTS conduit_migrations_log (
+  id BIGSERIAL NOT NULL,
+  namespace VARCHAR(255) NOT NULL DEFAULT '',
+  version VARCHAR(255) NOT NULL,
+  name VARCHAR(4095) NOT NULL,
+  direction VARCHAR(4) NOT NULL,
+  outcome VARCHAR(16) NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NOT NULL,
+  duration_ms BIGINT NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  db_user TEXT NOT NULL DEFAULT CURRENT_USER,
+  application_name TEXT NOT NULL DEFAULT current_setting('application_name'),
+  hostname TEXT NOT NULL DEFAULT '',
+  conduit_version VARCHAR(255) NOT NULL DEFAULT '',
+  allowed_hazards TEXT[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY (id)
 );
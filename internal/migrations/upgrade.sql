@@ -0,0 +1,79 @@
+-- Brings a history table created by an earlier version of conduit up to
+-- date. It only changes the table when something is missing, so that running
+-- it again is harmless.
+DO $$
+DECLARE
+  con RECORD;
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_attribute
+    WHERE attrelid = 'conduit_migrations'::REGCLASS AND attname = 'namespace' AND NOT attisdropped
+  ) THEN
+    ALTER TABLE conduit_migrations ADD COLUMN IF NOT EXISTS namespace VARCHAR(255) NOT NULL DEFAULT '';
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_attribute
+    WHERE attrelid = 'conduit_migrations'::REGCLASS AND attname = 'checksum' AND NOT attisdropped
+  ) THEN
+    ALTER TABLE conduit_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64) NOT NULL DEFAULT '';
+  END IF;
+
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_attribute
+    WHERE attrelid = 'conduit_migrations'::REGCLASS AND attname = 'last_statement' AND NOT attisdropped
+  ) THEN
+    ALTER TABLE conduit_migrations
+      ADD COLUMN IF NOT EXISTS dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+      ADD COLUMN IF NOT EXISTS last_statement INT NOT NULL DEFAULT 0;
+  END IF;
+
+  -- Replace the UNIQUE (version, name) key of tables created before
+  -- migrations had a namespace.
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint c
+    WHERE c.conrelid = 'conduit_migrations'::REGCLASS
+      AND c.contype = 'u'
+      AND ARRAY(
+        SELECT a.attname::TEXT FROM pg_attribute a
+        WHERE a.attrelid = c.conrelid AND a.attnum = ANY (c.conkey)
+        ORDER BY a.attname
+      ) = ARRAY['name', 'namespace', 'version']
+  ) THEN
+    FOR con IN
+      SELECT c.conrelid::REGCLASS AS tbl, c.conname FROM pg_constraint c
+      WHERE c.conrelid = 'conduit_migrations'::REGCLASS
+        AND c.contype = 'u'
+        AND ARRAY(
+          SELECT a.attname::TEXT FROM pg_attribute a
+          WHERE a.attrelid = c.conrelid AND a.attnum = ANY (c.conkey)
+          ORDER BY a.attname
+        ) = ARRAY['name', 'version']
+    LOOP
+      EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', con.tbl, con.conname);
+    END LOOP;
+
+    ALTER TABLE conduit_migrations ADD UNIQUE (namespace, version, name);
+  END IF;
+END
+$$;
+
+-- The migration log kept alongside the history table, see schema.sql.
+CREATE TABLE IF NOT EXISTS conduit_migrations_log (
+  id BIGSERIAL NOT NULL,
+  namespace VARCHAR(255) NOT NULL DEFAULT '',
+  version VARCHAR(255) NOT NULL,
+  name VARCHAR(4095) NOT NULL,
+  direction VARCHAR(4) NOT NULL,
+  outcome VARCHAR(16) NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL,
+  finished_at TIMESTAMPTZ NOT NULL,
+  duration_ms BIGINT NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  db_user TEXT NOT NULL DEFAULT CURRENT_USER,
+  application_name TEXT NOT NULL DEFAULT current_setting('application_name'),
+  hostname TEXT NOT NULL DEFAULT '',
+  conduit_version VARCHAR(255) NOT NULL DEFAULT '',
+  allowed_hazards TEXT[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY (id)
+);
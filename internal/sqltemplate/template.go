@@ -0,0 +1,54 @@
+// Package sqltemplate renders SQL migration files declared as templates.
+package sqltemplate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Directive, when present as a line of a SQL migration file, declares the
+// file a template.
+const Directive = "---- template ----"
+
+//nolint:gochecknoglobals
+var funcs = template.FuncMap{
+	"ident": func(parts ...string) string { return pgx.Identifier(parts).Sanitize() },
+	"literal": func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	},
+}
+
+// Is reports whether content declares [Directive].
+func Is(content []byte) bool {
+	for line := range bytes.Lines(content) {
+		if string(bytes.TrimSpace(line)) == Directive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render renders the migration file name with content as a text/template
+// with vars. A template that refers to a variable not in vars fails to
+// render.
+func Render(name string, content []byte, vars map[string]any) ([]byte, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+// Package tracing holds the OpenTelemetry helpers shared by conduit
+// packages.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the tracer conduit creates spans with.
+const InstrumentationName = "go.inout.gg/conduit"
+
+// Tracer returns the conduit tracer of tp, or of the global provider when
+// tp is nil.
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return tp.Tracer(InstrumentationName)
+}
+
+// FromContext returns the conduit tracer of the provider that created the
+// span in ctx, so that nested operations are traced into the same provider
+// as their caller. Without a span in ctx, the returned tracer is a no-op.
+func FromContext(ctx context.Context) trace.Tracer {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(InstrumentationName)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,207 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/internaldebug"
+)
+
+// lockRetryInterval is how often a Migrator configured with a lock timeout
+// polls for the advisory lock.
+const lockRetryInterval = 250 * time.Millisecond
+
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// LockSession describes a database session holding or waiting for the
+// migration advisory lock.
+type LockSession struct {
+	QueryStart      time.Time
+	User            string
+	ApplicationName string
+	State           string
+	Query           string
+	PID             int32
+	Granted         bool
+}
+
+// LockStatus describes the migration advisory lock. Holder is nil when the
+// lock is free.
+type LockStatus struct {
+	Holder  *LockSession
+	Waiters []*LockSession
+	Key     int64
+}
+
+// LockStatus reports which session holds the migration advisory lock and
+// which sessions are waiting for it, as seen in pg_locks and
+// pg_stat_activity.
+func (m *Migrator) LockStatus(ctx context.Context, db DB) (*LockStatus, error) {
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	rows, err := dbsqlc.New().LockSessions(ctx, s.conn, m.lockNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lock sessions: %w", err)
+	}
+
+	status := &LockStatus{Key: m.lockNum, Holder: nil, Waiters: nil}
+
+	for _, row := range rows {
+		session := &LockSession{
+			QueryStart:      row.QueryStart.Time,
+			User:            row.Usename,
+			ApplicationName: row.ApplicationName.String,
+			State:           row.State,
+			Query:           row.Query.String,
+			PID:             row.Pid.Int32,
+			Granted:         row.Granted.Bool,
+		}
+
+		if session.Granted {
+			status.Holder = session
+		} else {
+			status.Waiters = append(status.Waiters, session)
+		}
+	}
+
+	return status, nil
+}
+
+// acquireLock takes the migration advisory lock on s, waiting for at most
+// the configured lock timeout. Inside a caller-supplied transaction, a
+// transaction-level lock is taken instead, which is released when the
+// transaction ends.
+func (m *Migrator) acquireLock(ctx context.Context, s *session) (err error) {
+	start := time.Now()
+	defer func() { m.observer.ObserveLock(time.Since(start), err) }()
+
+	q := dbsqlc.New()
+
+	lock, tryLock := q.AcquireLock, q.TryAcquireLock
+	if s.tx != nil {
+		lock, tryLock = q.AcquireXactLock, q.TryAcquireXactLock
+	}
+
+	if m.lockTimeout <= 0 {
+		if err := lock(ctx, s.conn, m.lockNum); err != nil {
+			return fmt.Errorf("failed to acquire a lock: %w", err)
+		}
+
+		return nil
+	}
+
+	deadline := time.Now().Add(m.lockTimeout)
+
+	for {
+		ok, err := tryLock(ctx, s.conn, m.lockNum)
+		if err != nil {
+			return fmt.Errorf("failed to acquire a lock: %w", err)
+		}
+
+		if ok {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return m.lockTimeoutError(ctx, s)
+		}
+
+		internaldebug.Log("lock is held by another session, retrying in %s", min(lockRetryInterval, remaining))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to acquire a lock: %w", ctx.Err())
+		case <-time.After(min(lockRetryInterval, remaining)):
+		}
+	}
+}
+
+// lockTimeoutError returns [ErrLockTimeout], naming the holder of the lock
+// when it can be determined.
+func (m *Migrator) lockTimeoutError(ctx context.Context, s *session) error {
+	status, err := m.LockStatus(ctx, s.conn)
+	if err != nil || status.Holder == nil {
+		return fmt.Errorf("%w after %s", ErrLockTimeout, m.lockTimeout)
+	}
+
+	return fmt.Errorf(
+		"%w after %s: held by pid %d (application_name=%q, query_start=%s)",
+		ErrLockTimeout,
+		m.lockTimeout,
+		status.Holder.PID,
+		status.Holder.ApplicationName,
+		status.Holder.QueryStart.Format(time.RFC3339),
+	)
+}
+
+// lockHistory takes the migration advisory lock on s, as
+// [Migrator.acquireLock] does, and then brings the history table up to
+// date, see [Migrator.upgradeHistory]. When it fails, the lock is not held.
+func (m *Migrator) lockHistory(ctx context.Context, s *session) error {
+	if err := m.acquireLock(ctx, s); err != nil {
+		return err
+	}
+
+	if err := m.upgradeHistory(ctx, s.conn); err != nil {
+		m.releaseLock(ctx, s)
+
+		return err
+	}
+
+	return nil
+}
+
+// unlock releases the migration advisory lock and then the session.
+func (m *Migrator) unlock(ctx context.Context, s *session) {
+	defer s.close()
+
+	m.releaseLock(ctx, s)
+}
+
+// releaseLock releases the migration advisory lock. It runs even if ctx is
+// already cancelled, so that the session does not keep the lock.
+func (m *Migrator) releaseLock(ctx context.Context, s *session) {
+	if s.tx != nil {
+		return
+	}
+
+	if err := dbsqlc.New().ReleaseLock(context.WithoutCancel(ctx), s.conn, m.lockNum); err != nil {
+		m.logger.WarnContext(ctx, "failed to release the migration lock", "error", err)
+	}
+}
+
+// withLock returns an iterator over seq that releases the advisory lock and
+// the session once the iteration completes or is stopped early. The returned
+// iterator is single-use: ranging over it again yields nothing.
+func (m *Migrator) withLock(
+	ctx context.Context,
+	s *session,
+	seq iter.Seq2[*MigrationResult, error],
+) iter.Seq2[*MigrationResult, error] {
+	var done bool
+
+	return func(yield func(*MigrationResult, error) bool) {
+		if done {
+			return
+		}
+
+		done = true
+
+		defer m.unlock(ctx, s)
+
+		for result, err := range seq {
+			if !yield(result, err) {
+				return
+			}
+		}
+	}
+}
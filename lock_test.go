@@ -0,0 +1,81 @@
+package conduit_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Lock(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+	}
+
+	t.Run("should hold the lock until iteration completes", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+
+		other, err := pool.Acquire(t.Context())
+		require.NoError(t, err)
+		t.Cleanup(other.Release)
+
+		// Act
+		status, err := m.LockStatus(t.Context(), other.Conn())
+		require.NoError(t, err)
+
+		heldDuring := status.Holder != nil
+		testutil.CollectSeq2(t, seq)
+
+		status, err = m.LockStatus(t.Context(), other.Conn())
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, heldDuring, "lock should be held before iteration")
+		assert.Nil(t, status.Holder, "lock should be released after iteration")
+	})
+
+	t.Run("should return ErrLockTimeout, when lock is held by another session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		holder := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := holder.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { testutil.CollectSeq2(t, seq) })
+
+		other, err := pool.Acquire(t.Context())
+		require.NoError(t, err)
+		t.Cleanup(other.Release)
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithSkipSchemaDriftCheck(),
+			conduit.WithLockTimeout(100*time.Millisecond),
+		)
+
+		// Act
+		_, err = m.Migrate(t.Context(), conduit.DirectionUp, other.Conn(), nil)
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrLockTimeout)
+		assert.ErrorContains(t, err, fmt.Sprintf("held by pid %d", conn.PgConn().PID()))
+	})
+}
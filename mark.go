@@ -0,0 +1,229 @@
+package conduit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+// MarkApplied records the registry migrations of the given version as
+// applied without running them, and returns their keys. Migrations that are
+// already applied are skipped. When the registry has no migration of that
+// version, [ErrUnknownTarget] is returned.
+//
+// The recorded schema hash is that of the live schema, as if the migrations
+// had just been applied, so the schema drift check passes afterwards as long
+// as the schema really is in the state the migrations describe. The history
+// table is created when the database has none yet.
+func (m *Migrator) MarkApplied(
+	ctx context.Context,
+	db DB,
+	version conduitversion.Version,
+) ([]string, error) {
+	if !m.hasVersion(version) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, version.String())
+	}
+
+	return m.markApplied(ctx, db, func(s *MigrationStatus) bool {
+		return s.Version.Compare(version) == 0
+	})
+}
+
+// Baseline records every pending registry migration up to and including
+// version to as applied without running them, and returns their keys;
+// migrations skipped by the tag filter set with [WithTags] are left out. It is
+// meant for adopting a database whose schema already exists. When the
+// registry has no migration of that version, [ErrUnknownTarget] is returned.
+//
+// The recorded schema hash is computed, and the history table created, as in
+// [Migrator.MarkApplied].
+func (m *Migrator) Baseline(
+	ctx context.Context,
+	db DB,
+	to conduitversion.Version,
+) ([]string, error) {
+	if !m.hasVersion(to) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, to.String())
+	}
+
+	return m.markApplied(ctx, db, func(s *MigrationStatus) bool {
+		return s.State != MigrationStateSkipped && s.Version.Compare(to) <= 0
+	})
+}
+
+// MarkUnapplied removes the history rows of the given version without
+// running the down migrations, and returns the removed keys. Rows of
+// migrations missing from the registry can be removed too. When no migration
+// of that version is recorded, [ErrMigrationNotApplied] is returned.
+//
+// The schema drift check afterwards compares the live schema against the
+// hash recorded by the latest remaining migration.
+func (m *Migrator) MarkUnapplied(
+	ctx context.Context,
+	db DB,
+	version conduitversion.Version,
+) ([]string, error) {
+	var marked []string
+
+	err := m.withHistoryTx(ctx, db, func(conn *pgx.Conn, tx pgx.Tx) error {
+		report, err := m.Status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		recorded := sliceutil.Filter(report.Migrations, func(s *MigrationStatus) bool {
+			return !s.isPending() && s.Version.Compare(version) == 0
+		})
+		if len(recorded) == 0 {
+			return fmt.Errorf("%w: %s", ErrMigrationNotApplied, version.String())
+		}
+
+		history := dbsqlc.WithTable(tx, m.table)
+
+		for _, s := range recorded {
+			if err := dbsqlc.New().RollbackMigration(ctx, history, dbsqlc.RollbackMigrationParams{
+				Namespace: s.Namespace,
+				Version:   s.Version.String(),
+				Name:      s.Name,
+			}); err != nil {
+				return fmt.Errorf("failed to mark migration %s as unapplied: %w", s.Key(), err)
+			}
+
+			if err := writeLog(ctx, tx, m.table, newMarkedLogEntry(s, DirectionDown)); err != nil {
+				return err
+			}
+
+			marked = append(marked, s.Key())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return marked, nil
+}
+
+// markApplied records the pending migrations matching f as applied, whether
+// or not the tag filter skips them. The history table is created when it is
+// missing, as on a database that was never migrated.
+func (m *Migrator) markApplied(
+	ctx context.Context,
+	db DB,
+	f func(*MigrationStatus) bool,
+) ([]string, error) {
+	var marked []string
+
+	err := m.historyTx(ctx, db, true, func(conn *pgx.Conn, tx pgx.Tx) error {
+		report, err := m.Status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		pending := sliceutil.Filter(report.Migrations, func(s *MigrationStatus) bool {
+			return s.isPending() && f(s)
+		})
+		if len(pending) == 0 {
+			return nil
+		}
+
+		hash, err := m.schemaHash(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		history := dbsqlc.WithTable(tx, m.table)
+
+		for _, s := range pending {
+			if err := dbsqlc.New().ApplyMigration(ctx, history, dbsqlc.ApplyMigrationParams{
+				Namespace: s.Namespace,
+				Version:   s.Version.String(),
+				Name:      s.Name,
+				Hash:      hash,
+				Checksum:  s.Migration.Checksum(DirectionUp),
+			}); err != nil {
+				return fmt.Errorf("failed to mark migration %s as applied: %w", s.Key(), err)
+			}
+
+			if err := writeLog(ctx, tx, m.table, newMarkedLogEntry(s, DirectionUp)); err != nil {
+				return err
+			}
+
+			marked = append(marked, s.Key())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return marked, nil
+}
+
+// withHistoryTx runs f with the advisory lock held, inside a transaction
+// (or a savepoint of the caller-supplied one) that is committed when f
+// succeeds.
+func (m *Migrator) withHistoryTx(
+	ctx context.Context,
+	db DB,
+	f func(*pgx.Conn, pgx.Tx) error,
+) error {
+	return m.historyTx(ctx, db, false, f)
+}
+
+// historyTx is [Migrator.withHistoryTx] that, when create is set, first
+// creates the history table if it is missing. The table is created outside
+// the transaction, so that the schema hash f records covers it.
+func (m *Migrator) historyTx(
+	ctx context.Context,
+	db DB,
+	create bool,
+	f func(*pgx.Conn, pgx.Tx) error,
+) error {
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if err := m.lockHistory(ctx, s); err != nil {
+		s.close()
+
+		return err
+	}
+	defer m.unlock(ctx, s)
+
+	if create {
+		if err := m.createHistory(ctx, s.conn); err != nil {
+			return err
+		}
+	}
+
+	begin := s.conn.Begin
+	if s.tx != nil {
+		begin = s.tx.Begin
+	}
+
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := f(s.conn, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Baseline(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql":   "CREATE TABLE a (id INT);",
+		"20230601120000_create_a.down.sql": "DROP TABLE a;",
+		"20230602120000_create_b.up.sql":   "CREATE TABLE b (id INT);",
+		"20230602120000_create_b.down.sql": "DROP TABLE b;",
+		"20230603120000_create_c.up.sql":   "CREATE TABLE c (id INT);",
+		"20230603120000_create_c.down.sql": "DROP TABLE c;",
+	}
+
+	t.Run("should apply only later migrations, when database is baselined", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		_, err := conn.Exec(t.Context(), "CREATE TABLE a (id INT); CREATE TABLE b (id INT);")
+		require.NoError(t, err)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		marked, err := m.Baseline(t.Context(), conn, parseVersion(t, "20230602120000"))
+		require.NoError(t, err)
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_a", "20230602120000_create_b"}, marked)
+
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 1)
+		assert.Equal(t, "20230603120000_create_c", results[0].Key())
+		assert.Len(t, appliedMigrations(t, pool), 3)
+	})
+
+	t.Run("should create history table, when database has none", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		testutil.Exec(t, pool, "DROP TABLE conduit_migrations, conduit_migrations_log;")
+		testutil.Exec(t, pool, "CREATE TABLE a (id INT);")
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		marked, err := m.Baseline(t.Context(), conn, parseVersion(t, "20230601120000"))
+		require.NoError(t, err)
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230601120000_create_a"}, marked)
+		assert.True(t, tenantTableExists(t, pool, "public", "conduit_migrations_log"))
+
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2, "the drift check passes against the recorded hash")
+		assert.Len(t, appliedMigrations(t, pool), 3)
+	})
+
+	t.Run("should return error, when version is not in registry", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		_, err := m.Baseline(t.Context(), conn, parseVersion(t, "20230602130000"))
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrUnknownTarget)
+	})
+}
+
+func TestMigrator_Mark(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql":   "CREATE TABLE a (id INT);",
+		"20230601120000_create_a.down.sql": "DROP TABLE a;",
+		"20230602120000_create_b.up.sql":   "CREATE TABLE b (id INT);",
+		"20230602120000_create_b.down.sql": "DROP TABLE b;",
+	}
+
+	t.Run("should record migration without running it, when marked applied", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		marked, err := m.MarkApplied(t.Context(), conn, parseVersion(t, "20230602120000"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230602120000_create_b"}, marked)
+		assert.Len(t, appliedMigrations(t, pool), 1)
+		assert.False(t, testutil.TableExists(t, pool, "b"))
+	})
+
+	t.Run("should remove history row without running down, when marked unapplied", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		marked, err := m.MarkUnapplied(t.Context(), conn, parseVersion(t, "20230602120000"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20230602120000_create_b"}, marked)
+		assert.Len(t, appliedMigrations(t, pool), 1)
+		assert.True(t, testutil.TableExists(t, pool, "b"))
+	})
+
+	t.Run("should return error, when marking unapplied a pending version", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		_, err := m.MarkUnapplied(t.Context(), conn, parseVersion(t, "20230601120000"))
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrMigrationNotApplied)
+	})
+}
@@ -1,14 +1,12 @@
 package conduit
 
 import (
-	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"hash/fnv"
 	"iter"
 	"log/slog"
-	"maps"
 	"slices"
 	"strings"
 	"time"
@@ -17,12 +15,15 @@ import (
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/stripe/pg-schema-diff/pkg/schema"
 	"go.inout.gg/foundations/debug"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.inout.gg/conduit/conduitregistry"
 	"go.inout.gg/conduit/internal/dbsqlc"
 	"go.inout.gg/conduit/internal/direction"
 	"go.inout.gg/conduit/internal/internaldebug"
+	"go.inout.gg/conduit/internal/migrations"
 	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/tracing"
 	"go.inout.gg/conduit/pkg/conduitversion"
 	"go.inout.gg/conduit/pkg/stopwatch"
 )
@@ -46,21 +47,48 @@ var (
 	)
 	ErrSchemaDrift    = errors.New("schema drift detected")
 	ErrHazardDetected = errors.New("hazardous migration detected")
+	ErrUnknownTarget  = errors.New("target version not found in registry")
+	ErrInvalidTarget  = errors.New("invalid migration target")
 )
 
 type (
-	Direction = direction.Direction
-	Migration = conduitregistry.Migration
+	Direction   = direction.Direction
+	Migration   = conduitregistry.Migration
+	Setting     = conduitregistry.Setting
+	RetryPolicy = conduitregistry.RetryPolicy
 )
 
+// DefaultLockKey identifies the advisory lock taken by a Migrator using the
+// default history table.
+const DefaultLockKey = "conduit"
+
 type config struct {
 	Logger               *slog.Logger
 	Registry             *conduitregistry.Registry
 	Executor             MigrationExecutor
+	Hooks                Hooks
+	TracerProvider       trace.TracerProvider
+	Observer             Observer
+	HistoryTable         string
+	HistorySchema        string
+	LockKey              string
+	LockTimeout          time.Duration
+	OutOfOrderPolicy     ConsistencyPolicy
+	OrphanedPolicy       ConsistencyPolicy
+	Settings             []Setting
+	RetryPolicy          RetryPolicy
+	Tags                 TagFilter
+	ExcludeSchemas       []string
 	SkipSchemaDriftCheck bool
+	VerifySchema         bool
+
+	// SkipSchemaHash records applied migrations without a schema hash; it
+	// is set by [TenantRunner] only.
+	SkipSchemaHash bool
 }
 
-// Option configures a Migrator.
+// Option configures a Migrator, or the Migrator of every tenant of a
+// [TenantRunner].
 type Option func(*config)
 
 // WithLogger sets the logger used by the Migrator for debug output.
@@ -80,12 +108,129 @@ func WithExecutor(e MigrationExecutor) Option {
 	return func(c *config) { c.Executor = e }
 }
 
+// WithHooks sets the callbacks run around each migration run, migration and
+// statement, see [Hooks].
+func WithHooks(h Hooks) Option {
+	return func(c *config) { c.Hooks = h }
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider Migrate, each
+// migration, the schema drift check and schema hashing create spans with.
+// When omitted, the global provider is used, which is a no-op unless the
+// application configures one. See also [NewQueryTracer].
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.TracerProvider = tp }
+}
+
+// WithObserver sets the [Observer] that receives measurements of lock
+// waits, drift checks, blocked hazards, migrations and pending migrations.
+func WithObserver(o Observer) Option {
+	return func(c *config) { c.Observer = o }
+}
+
 // WithSkipSchemaDriftCheck disables the schema drift check that runs before
 // applying up migrations.
 func WithSkipSchemaDriftCheck() Option {
 	return func(c *config) { c.SkipSchemaDriftCheck = true }
 }
 
+// WithExcludeSchemas leaves the given schemas out of the reports built by
+// [Migrator.Drift] and schema verification, and attached to a
+// [SchemaDriftError]. The schema hash the drift check compares still covers
+// them.
+func WithExcludeSchemas(schemas ...string) Option {
+	return func(c *config) { c.ExcludeSchemas = schemas }
+}
+
+// WithSchemaVerification enables verifying the schema after each migration
+// in either direction: the live schema is compared with the schema built by
+// replaying the applied migrations in a temporary database on the same
+// instance, see [Migrator.Drift]. A mismatch stops the run with a
+// [*SchemaVerificationError].
+//
+// Each verification replays every applied migration, and the database user
+// must be allowed to create databases. Go migrations cannot be replayed, so
+// the objects they create fail the verification. Dry runs and runs inside a
+// caller-supplied transaction are not verified.
+func WithSchemaVerification() Option {
+	return func(c *config) { c.VerifySchema = true }
+}
+
+// WithDefaultSettings sets the configuration parameters every migration
+// runs with, unless it sets the same parameter with a directive, such as
+// ---- lock-timeout: 3s ----. See [conduitregistry.Setting], and
+// [conduitregistry.LockTimeout] and [conduitregistry.StatementTimeout] for
+// the common timeouts.
+func WithDefaultSettings(settings ...Setting) Option {
+	return func(c *config) { c.Settings = settings }
+}
+
+// WithRetryPolicy sets how migrations that fail on a lock timeout or a
+// deadlock are retried, unless they declare their own policy with a
+// directive, such as ---- retry: 5 backoff=2s ----. By default, migrations
+// are not retried.
+//
+// A migration that runs in a transaction is retried as a whole. A migration
+// that runs outside one is retried only when its first statement fails, as
+// the statements before a later one are already applied; the same holds for
+// the statement a resumed migration continues from.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *config) { c.RetryPolicy = p }
+}
+
+// WithTags sets the tagged migrations Migrate applies, unless a call sets
+// its own [MigrateOptions].Tags, and that [Migrator.Status] reports as
+// pending; others are reported as skipped. By default, no tagged migration
+// is selected.
+func WithTags(f TagFilter) Option {
+	return func(c *config) { c.Tags = f }
+}
+
+// WithHistoryTable sets the name of the table applied migrations are
+// recorded in. Defaults to "conduit_migrations".
+//
+// Use a distinct table, together with [WithLockKey], when several
+// independent applications share one database.
+func WithHistoryTable(name string) Option {
+	return func(c *config) { c.HistoryTable = name }
+}
+
+// WithHistorySchema sets the Postgres schema of the history table. When
+// omitted, the table is resolved through the connection's search_path.
+func WithHistorySchema(schema string) Option {
+	return func(c *config) { c.HistorySchema = schema }
+}
+
+// WithLockKey sets the identity of the advisory lock held while migrating.
+// Defaults to [DefaultLockKey] for the default history table, and to the
+// qualified table name otherwise.
+func WithLockKey(key string) Option {
+	return func(c *config) { c.LockKey = key }
+}
+
+// WithOutOfOrderPolicy sets how the Migrator reacts to pending migrations
+// older than the newest applied one. Defaults to [ConsistencyAllow].
+//
+// Under [ConsistencyError], Migrate returns an [*OutOfOrderError].
+func WithOutOfOrderPolicy(p ConsistencyPolicy) Option {
+	return func(c *config) { c.OutOfOrderPolicy = p }
+}
+
+// WithOrphanedPolicy sets how the Migrator reacts to applied migrations
+// that are missing from the registry. Defaults to [ConsistencyAllow].
+//
+// Under [ConsistencyError], Migrate returns an [*OrphanedMigrationsError].
+func WithOrphanedPolicy(p ConsistencyPolicy) Option {
+	return func(c *config) { c.OrphanedPolicy = p }
+}
+
+// WithLockTimeout bounds how long Migrate waits for the advisory lock held
+// by another session. When the timeout elapses, [ErrLockTimeout] is returned.
+// By default, Migrate waits indefinitely.
+func WithLockTimeout(d time.Duration) Option {
+	return func(c *config) { c.LockTimeout = d }
+}
+
 func (c *config) defaults() {
 	if c.Logger == nil {
 		c.Logger = slog.Default()
@@ -98,15 +243,47 @@ func (c *config) defaults() {
 	if c.Executor == nil {
 		c.Executor = NewLiveExecutor(c.Logger, stopwatch.Standard{})
 	}
+
+	if c.Observer == nil {
+		c.Observer = nopObserver{}
+	}
+
+	if c.OutOfOrderPolicy == "" {
+		c.OutOfOrderPolicy = ConsistencyAllow
+	}
+
+	if c.OrphanedPolicy == "" {
+		c.OrphanedPolicy = ConsistencyAllow
+	}
+
+	if c.LockKey == "" {
+		if table := migrations.NewTable(c.HistorySchema, c.HistoryTable); table.IsDefault() {
+			c.LockKey = DefaultLockKey
+		} else {
+			c.LockKey = table.String()
+		}
+	}
 }
 
 // MigrationResult holds the outcome of a single applied migration.
+//
+// Attempts lists every attempt at running the migration, the last of which
+// succeeded; there is more than one when it was retried, see
+// [WithRetryPolicy]. DurationTotal includes the backoff between attempts.
 type MigrationResult struct {
 	Version       conduitversion.Version
+	Direction     Direction
+	Namespace     string
 	Name          string
+	Attempts      []MigrationAttempt
 	DurationTotal time.Duration
 }
 
+// Key returns the identifier of the migration, see [conduitregistry.Key].
+func (r *MigrationResult) Key() string {
+	return conduitregistry.Key(r.Namespace, r.Version.String(), r.Name)
+}
+
 // MigrateOptions configures a single [Migrator.Migrate] call.
 //
 // Steps controls how many migrations to apply. Use [AllSteps] (-1) to apply
@@ -115,12 +292,47 @@ type MigrationResult struct {
 //
 // AllowHazards lists hazard types that are permitted to proceed. Migrations
 // containing unlisted hazards cause [ErrHazardDetected].
+//
+// To migrates to the state right after the migrations of the given version:
+// up applies pending migrations up to and including To, down rolls back
+// applied migrations newer than To. The version must exist in the registry,
+// otherwise [ErrUnknownTarget] is returned. ToTime does the same for a
+// wall-clock timestamp that does not need to match any migration. When a
+// target is set, Steps defaults to [AllSteps].
+//
+// Settings adds to, and overrides by parameter name, the settings set with
+// [WithDefaultSettings] for this call; migrations that set a parameter with
+// a directive keep their own value.
+//
+// Tags selects the tagged migrations to apply, in place of the filter set
+// with [WithTags]; pending migrations it leaves out are logged and skipped.
+// Rolling back is not filtered.
 type MigrateOptions struct {
+	ToTime       time.Time
 	AllowHazards []HazardType
+	Settings     []Setting
+	Tags         TagFilter
+	To           conduitversion.Version
 	Steps        int
 }
 
+// target returns the version cut-off requested by To or ToTime.
+func (m *MigrateOptions) target() (conduitversion.Version, bool) {
+	switch {
+	case !m.To.IsZero():
+		return m.To, true
+	case !m.ToTime.IsZero():
+		return conduitversion.NewFromTime(m.ToTime), true
+	}
+
+	return conduitversion.Version{}, false
+}
+
 func (m *MigrateOptions) defaults(dir direction.Direction) {
+	if _, ok := m.target(); ok && m.Steps == 0 {
+		m.Steps = AllSteps
+	}
+
 	if m.Steps == 0 {
 		if dir == direction.DirectionUp {
 			m.Steps = DefaultUpStep
@@ -135,7 +347,21 @@ type Migrator struct {
 	logger               *slog.Logger
 	registry             *conduitregistry.Registry
 	executor             MigrationExecutor
+	hooks                Hooks
+	tracer               trace.Tracer
+	observer             Observer
+	table                migrations.Table
+	lockNum              int64
+	lockTimeout          time.Duration
+	outOfOrderPolicy     ConsistencyPolicy
+	orphanedPolicy       ConsistencyPolicy
+	settings             []Setting
+	retryPolicy          RetryPolicy
+	tags                 TagFilter
+	excludeSchemas       []string
 	skipSchemaDriftCheck bool
+	skipSchemaHash       bool
+	verifySchema         bool
 }
 
 // NewMigrator creates a Migrator configured with the given options.
@@ -146,16 +372,42 @@ func NewMigrator(opts ...Option) *Migrator {
 		c(cfg)
 	}
 
+	return newMigrator(cfg)
+}
+
+// newMigrator creates a Migrator from cfg, filling in its defaults.
+func newMigrator(cfg *config) *Migrator {
 	cfg.defaults()
 
 	debug.Assert(cfg.Logger != nil, "config.Logger must be defined")
 	debug.Assert(cfg.Registry != nil, "config.Registry must be defined")
 
+	table := migrations.NewTable(cfg.HistorySchema, cfg.HistoryTable)
+
+	executor := cfg.Executor
+	if e, ok := executor.(tableScopedExecutor); ok {
+		executor = e.withHistory(table, cfg.SkipSchemaHash)
+	}
+
 	return &Migrator{
 		logger:               cfg.Logger,
 		registry:             cfg.Registry,
-		executor:             cfg.Executor,
+		executor:             executor,
+		hooks:                cfg.Hooks,
+		tracer:               tracing.Tracer(cfg.TracerProvider),
+		observer:             cfg.Observer,
+		table:                table,
+		lockNum:              pgLockNum(cfg.LockKey),
+		lockTimeout:          cfg.LockTimeout,
+		outOfOrderPolicy:     cfg.OutOfOrderPolicy,
+		orphanedPolicy:       cfg.OrphanedPolicy,
+		settings:             cfg.Settings,
+		retryPolicy:          cfg.RetryPolicy,
+		tags:                 cfg.Tags,
+		excludeSchemas:       cfg.ExcludeSchemas,
 		skipSchemaDriftCheck: cfg.SkipSchemaDriftCheck,
+		skipSchemaHash:       cfg.SkipSchemaHash,
+		verifySchema:         cfg.VerifySchema && !isDryRun(executor),
 	}
 }
 
@@ -164,17 +416,32 @@ func NewMigrator(opts ...Option) *Migrator {
 //
 // The returned iterator yields individual [MigrationResult] values as each
 // migration completes. If a migration fails, the iterator yields the error
-// and stops. The advisory lock is held for the duration of the iteration.
+// and stops. The advisory lock is held until the iteration completes or is
+// stopped early, so callers must range over the iterator; it can be ranged
+// over only once. When Migrate returns an error, the lock is already
+// released.
 //
 // When opts is nil, direction-specific defaults are used: all pending
 // migrations for up, one migration for down.
+//
+// When opts sets a target (To or ToTime), dir may be left empty: the
+// direction is then worked out from the applied migrations — down if any
+// applied migration is newer than the target, up otherwise.
+//
+// db may be a connection, a pool or a transaction, see [DB]. With a pool,
+// a dedicated connection is acquired for the whole run and released with
+// the lock. With a transaction, every migration runs inside it: transactional
+// migrations run in savepoints, and the lock is a transaction-level lock
+// released when the caller commits or rolls back. Schema hashes are always
+// computed on a separate connection, which does not see uncommitted changes,
+// so a caller-supplied transaction is best suited to tests that roll back.
 func (m *Migrator) Migrate(
 	ctx context.Context,
 	dir Direction,
-	conn *pgx.Conn,
+	db DB,
 	opts *MigrateOptions,
 ) (iter.Seq2[*MigrationResult, error], error) {
-	debug.Assert(conn != nil, "expected conn to be defined")
+	debug.Assert(db != nil, "expected db to be defined")
 
 	if opts == nil {
 		opts = new(MigrateOptions)
@@ -182,26 +449,94 @@ func (m *Migrator) Migrate(
 		internaldebug.Log("opts is omitted, create a new one")
 	}
 
-	opts.defaults(dir)
+	if !opts.To.IsZero() && !opts.ToTime.IsZero() {
+		return nil, fmt.Errorf("%w: To and ToTime are mutually exclusive", ErrInvalidTarget)
+	}
 
-	debug.Assert(opts.Steps == -1 || opts.Steps > 0, "invalid steps")
+	if !opts.To.IsZero() && !m.hasVersion(opts.To) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, opts.To.String())
+	}
+
+	ctx, span := m.tracer.Start(ctx, "conduit.migrate")
+
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		tracing.End(span, err)
+
+		return nil, err
+	}
+
+	ctx = s.context(ctx)
+
+	if err := m.lockHistory(ctx, s); err != nil {
+		s.close()
+		tracing.End(span, err)
+
+		return nil, err
+	}
 
-	lockNum := pgLockNum("conduit")
+	dir, migrations, err := m.plan(ctx, dir, s.conn, opts)
+	if err != nil {
+		m.unlock(ctx, s)
+		tracing.End(span, err)
 
-	if err := dbsqlc.New().AcquireLock(ctx, conn, lockNum); err != nil {
-		return nil, fmt.Errorf("failed to acquire a lock: %w", err)
+		return nil, err
 	}
 
-	defer func() { _ = dbsqlc.New().ReleaseLock(ctx, conn, lockNum) }()
+	span.SetAttributes(attrDirection.String(string(dir)), attrMigrations.Int(len(migrations)))
+
+	seq := m.applyMigrations(ctx, migrations, dir, s.conn, opts)
 
+	return m.withLock(ctx, s, traceSeq(span, seq)), nil
+}
+
+// plan resolves the direction and the migrations to run in it. It must be
+// called with the advisory lock held.
+func (m *Migrator) plan(
+	ctx context.Context,
+	dir Direction,
+	conn *pgx.Conn,
+	opts *MigrateOptions,
+) (Direction, []*Migration, error) {
 	var (
 		migrations []*conduitregistry.Migration
 		err        error
 	)
 
+	tags := m.tagFilter(opts)
+
+	report, err := m.status(ctx, conn, tags)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// A dirty migration must be dealt with first: the schema is
+	// half-migrated, so neither the drift check nor any migration can run.
+	if err := checkDirty(report); err != nil {
+		return "", nil, err
+	}
+
+	target, hasTarget := opts.target()
+
+	if dir == "" && hasTarget {
+		dir, err = m.targetDirection(ctx, conn, target)
+		if err != nil {
+			return "", nil, err
+		}
+
+		internaldebug.Log("resolved direction=%s for target=%s", dir, target.String())
+	}
+
+	opts.defaults(dir)
+
+	debug.Assert(opts.Steps == -1 || opts.Steps > 0, "invalid steps")
+
 	switch dir {
 	case DirectionUp:
 		migrations, err = m.upMigrations(ctx, conn)
+		if err == nil {
+			migrations = m.selectTagged(ctx, migrations, tags)
+		}
 	case DirectionDown:
 		migrations, err = m.downMigrations(ctx, conn)
 	default:
@@ -209,41 +544,106 @@ func (m *Migrator) Migrate(
 	}
 
 	if err != nil {
-		return nil, err
+		return "", nil, err
+	}
+
+	if hasTarget {
+		migrations = sliceutil.Filter(migrations, func(migration *Migration) bool {
+			if dir == DirectionUp {
+				return migration.Version().Compare(target) <= 0
+			}
+
+			return migration.Version().Compare(target) > 0
+		})
+	}
+
+	if opts.Steps != AllSteps {
+		migrations = migrations[0:min(opts.Steps, len(migrations))]
+	}
+
+	if err := verifyChecksums(report); err != nil {
+		return "", nil, err
+	}
+
+	if err := m.checkConsistency(ctx, report, dir, migrations); err != nil {
+		return "", nil, err
+	}
+
+	if err := checkDependencies(report, dir, migrations); err != nil {
+		return "", nil, err
 	}
 
-	return m.applyMigrations(ctx, migrations, dir, conn, opts), nil
+	return dir, migrations, nil
 }
 
-func (m *Migrator) existingMigrationKeys(ctx context.Context, conn *pgx.Conn) ([]string, error) {
-	ok, err := dbsqlc.New().DoesTableExist(ctx, conn, "conduit_migrations")
+// hasVersion reports whether the registry contains a migration with
+// version v.
+func (m *Migrator) hasVersion(v conduitversion.Version) bool {
+	for _, migration := range m.registry.Migrations() {
+		if migration.Version().Compare(v) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// targetDirection works out the direction needed to reach target: down if
+// any applied migration is newer than target, up otherwise.
+func (m *Migrator) targetDirection(
+	ctx context.Context,
+	conn *pgx.Conn,
+	target conduitversion.Version,
+) (Direction, error) {
+	applied, err := m.downMigrations(ctx, conn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from migrations table: %w", err)
+		return "", err
 	}
 
-	if !ok {
-		internaldebug.Log("conduitmigrations table is not found")
-		return []string{}, nil
+	if slices.ContainsFunc(applied, func(migration *Migration) bool {
+		return migration.Version().Compare(target) > 0
+	}) {
+		return DirectionDown, nil
 	}
 
-	rows, err := dbsqlc.New().AllExistingMigrations(ctx, conn)
+	return DirectionUp, nil
+}
+
+func (m *Migrator) existingMigrationKeys(ctx context.Context, conn *pgx.Conn) ([]string, error) {
+	var rows []dbsqlc.AllExistingMigrationsRow
+
+	ok, err := m.readHistory(ctx, conn, func() (err error) {
+		rows, err = dbsqlc.New().AllExistingMigrations(ctx, dbsqlc.WithTable(conn, m.table))
+		if err != nil {
+			return fmt.Errorf("failed to fetch existing migrations: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch existing migrations: %w", err)
+		return nil, err
+	}
+
+	if !ok {
+		internaldebug.Log("%s table is not found", m.table)
+		return []string{}, nil
 	}
 
 	keys := make([]string, len(rows))
 	for i, row := range rows {
-		keys[i] = row.Version + "_" + row.Name
+		keys[i] = conduitregistry.Key(row.Namespace, row.Version, row.Name)
 	}
 
 	return keys, nil
 }
 
+// upMigrations returns the migrations to apply: those not yet applied and
+// the repeatable migrations whose content changed since they were applied.
 func (m *Migrator) upMigrations(
 	ctx context.Context,
 	conn *pgx.Conn,
 ) ([]*conduitregistry.Migration, error) {
-	existingKeys, err := m.existingMigrationKeys(ctx, conn)
+	records, err := m.migrationRecords(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
@@ -255,14 +655,19 @@ func (m *Migrator) upMigrations(
 	}
 
 	targetMigrations := m.registry.Migrations()
-	for _, key := range existingKeys {
+	for _, record := range records {
+		key := conduitregistry.Key(record.Namespace, record.Version, record.Name)
+
+		if migration, ok := targetMigrations[key]; ok &&
+			migration.Version().IsRepeatable() &&
+			record.Checksum != migration.Checksum(DirectionUp) {
+			continue
+		}
+
 		delete(targetMigrations, key)
 	}
 
-	migrations := slices.Collect(maps.Values(targetMigrations))
-	slices.SortFunc(migrations, compareMigrations)
-
-	return migrations, nil
+	return m.sortedMigrations(targetMigrations)
 }
 
 func (m *Migrator) downMigrations(
@@ -279,41 +684,77 @@ func (m *Migrator) downMigrations(
 		existingKeysSet[key] = struct{}{}
 	}
 
+	// Repeatable migrations are never rolled back.
 	targetMigrations := m.registry.Migrations()
-	for key := range targetMigrations {
-		if _, ok := existingKeysSet[key]; !ok {
+	for key, migration := range targetMigrations {
+		if _, ok := existingKeysSet[key]; !ok || migration.Version().IsRepeatable() {
 			delete(targetMigrations, key)
 		}
 	}
 
-	migrations := slices.Collect(maps.Values(targetMigrations))
-	slices.SortFunc(migrations, func(a, b *Migration) int {
-		return compareMigrations(b, a)
-	})
+	migrations, err := m.sortedMigrations(targetMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(migrations)
 
 	return migrations, nil
 }
 
-func (m *Migrator) detectSchemaDrift(ctx context.Context, conn *pgx.Conn) error {
+// sortedMigrations returns the migrations of subset, a subset of the
+// registry, in the order they are applied, see [conduitregistry.Registry.Sorted].
+func (m *Migrator) sortedMigrations(subset map[string]*Migration) ([]*Migration, error) {
+	sorted, err := m.registry.Sorted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to order migrations: %w", err)
+	}
+
+	return sliceutil.Filter(sorted, func(migration *Migration) bool {
+		_, ok := subset[migration.Key()]
+		return ok
+	}), nil
+}
+
+func (m *Migrator) detectSchemaDrift(ctx context.Context, conn *pgx.Conn) (err error) {
 	internaldebug.Log("detecting schema drift")
 
-	ok, err := dbsqlc.New().DoesTableExist(ctx, conn, "conduit_migrations")
+	ctx, span := tracing.FromContext(ctx).Start(ctx, "conduit.drift_check")
+	defer func() {
+		m.observer.ObserveDriftCheck(err)
+		tracing.End(span, err)
+	}()
+
+	var (
+		expected string
+		recorded bool
+	)
+
+	ok, err := m.readHistory(ctx, conn, func() (err error) {
+		expected, err = dbsqlc.New().LatestSchemaHash(ctx, dbsqlc.WithTable(conn, m.table))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to fetch latest schema hash: %w", err)
+		}
+
+		recorded = true
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to check if migrations table exists: %w", err)
+		return err
 	}
 
 	if !ok {
-		internaldebug.Log("conduit_migrations table does not exist, skipping schema drift check")
+		internaldebug.Log("%s table does not exist, skipping schema drift check", m.table)
 		return nil
 	}
 
-	expected, err := dbsqlc.New().LatestSchemaHash(ctx, conn)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil
-		}
-
-		return fmt.Errorf("failed to fetch latest schema hash: %w", err)
+	if !recorded {
+		return nil
 	}
 
 	db := stdlib.OpenDB(*conn.Config())
@@ -325,12 +766,16 @@ func (m *Migrator) detectSchemaDrift(ctx context.Context, conn *pgx.Conn) error
 	}
 
 	if actual != expected {
-		return fmt.Errorf(
-			"%w: expected hash %s, got %s",
-			ErrSchemaDrift,
-			expected,
-			actual,
-		)
+		driftErr := &SchemaDriftError{Report: nil, Expected: expected, Actual: actual}
+
+		report, err := m.driftReport(ctx, conn)
+		if err != nil {
+			m.logger.WarnContext(ctx, "failed to build schema drift report", slog.Any("error", err))
+		} else {
+			driftErr.Report = report
+		}
+
+		return driftErr
 	}
 
 	return nil
@@ -344,19 +789,30 @@ func (m *Migrator) applyMigrations(
 	opts *MigrateOptions,
 ) iter.Seq2[*MigrationResult, error] {
 	return func(yield func(*MigrationResult, error) bool) {
-		if opts.Steps != AllSteps {
-			migrations = migrations[0:min(opts.Steps, len(migrations))]
-		}
-
 		internaldebug.Log(
 			"running migrations migrations=[%s] steps=%d total_migrations_count=%d",
 			strings.Join(sliceutil.Map(migrations, func(m *conduitregistry.Migration) string {
-				return fmt.Sprintf("name=%s version=%s", m.Name(), m.Version().String())
+				return fmt.Sprintf("key=%s", m.Key())
 			}), ", "),
 			opts.Steps,
 			len(migrations),
 		)
 
+		if err := m.hooks.beforeRun(ctx, conn, dir); err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		var (
+			runErr  error
+			stopped bool
+		)
+
+		ctx := withAllowedHazards(ctx, opts.AllowHazards)
+		ctx = withDefaultSettings(ctx, mergeSettings(m.settings, opts.Settings))
+		ctx = withRetryPolicy(ctx, m.retryPolicy)
+
 		for _, migration := range migrations {
 			internaldebug.Log(
 				"running migration name=%s version=%s direction=%s",
@@ -365,48 +821,86 @@ func (m *Migrator) applyMigrations(
 				dir,
 			)
 
-			if hazards := migration.Hazards(dir); len(hazards) > 0 {
-				var blocked []string
+			if err := m.checkHazards(migration, dir, opts.AllowHazards); err != nil {
+				runErr = err
 
-				for _, h := range hazards {
-					if !slices.Contains(opts.AllowHazards, h.Type) {
-						blocked = append(blocked, fmt.Sprintf("%s: %s", h.Type, h.Message))
-					}
-				}
+				break
+			}
 
-				if len(blocked) > 0 {
-					yield(nil, fmt.Errorf(
-						"%w: migration %s_%s contains hazards:\n  - %s",
-						ErrHazardDetected,
-						migration.Version().String(),
-						migration.Name(),
-						strings.Join(blocked, "\n  - "),
-					))
+			start := time.Now()
+			migrationResult, err := m.hooks.execute(
+				ctx, conn, migration, dir,
+				func(ctx context.Context) (MigrationResult, error) {
+					return m.executor.Execute(ctx, migration, dir, conn)
+				},
+			)
+			m.observer.ObserveMigration(migration, dir, time.Since(start), err)
 
-					return
-				}
+			if err != nil {
+				runErr = err
+
+				break
 			}
 
-			migrationResult, err := m.executor.Execute(ctx, migration, dir, conn)
-			if err != nil {
-				yield(nil, err)
+			if m.verifySchema {
+				if err := m.verify(ctx, conn, migration, dir); err != nil {
+					runErr = err
 
-				return
+					break
+				}
 			}
 
 			if !yield(&migrationResult, nil) {
-				return
+				stopped = true
+
+				break
 			}
 		}
+
+		if err := m.hooks.afterRun(ctx, conn, dir, runErr); err != nil {
+			runErr = errors.Join(runErr, err)
+		}
+
+		m.observePending(ctx, conn)
+
+		if runErr != nil && !stopped {
+			yield(nil, runErr)
+		}
 	}
 }
 
-func compareMigrations(a, b *conduitregistry.Migration) int {
-	if c := a.Version().Compare(b.Version()); c != 0 {
-		return c
+// checkHazards returns [ErrHazardDetected] when migration contains hazards
+// in direction dir that are not listed in allow.
+func (m *Migrator) checkHazards(migration *Migration, dir Direction, allow []HazardType) error {
+	blocked := sliceutil.Filter(migration.Hazards(dir), func(h conduitregistry.Hazard) bool {
+		return !slices.Contains(allow, h.Type)
+	})
+	if len(blocked) == 0 {
+		return nil
 	}
 
-	return cmp.Compare(a.Name(), b.Name())
+	m.observer.ObserveHazardsBlocked(migration, dir, blocked)
+
+	return fmt.Errorf(
+		"%w: migration %s contains hazards:\n  - %s",
+		ErrHazardDetected,
+		migration.Key(),
+		strings.Join(sliceutil.Map(blocked, func(h conduitregistry.Hazard) string {
+			return fmt.Sprintf("%s: %s", h.Type, h.Message)
+		}), "\n  - "),
+	)
+}
+
+// observePending reports the number of pending migrations once a run is
+// over. Status reports it to the observer as a side effect.
+func (m *Migrator) observePending(ctx context.Context, conn *pgx.Conn) {
+	if _, ok := m.observer.(nopObserver); ok {
+		return
+	}
+
+	if _, err := m.Status(context.WithoutCancel(ctx), conn); err != nil {
+		m.logger.WarnContext(ctx, "failed to count pending migrations", slog.Any("error", err))
+	}
 }
 
 func pgLockNum(s string) int64 {
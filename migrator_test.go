@@ -1,7 +1,9 @@
 package conduit_test
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"testing"
 	"time"
 
@@ -11,9 +13,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
 	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/migrations"
 	"go.inout.gg/conduit/internal/testregistry"
 	"go.inout.gg/conduit/internal/testutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
 )
 
 func newConn(t *testing.T) (*pgxpool.Pool, *pgx.Conn) {
@@ -37,6 +42,15 @@ func appliedMigrations(t *testing.T, pool *pgxpool.Pool) []dbsqlc.TestAllMigrati
 	return rows
 }
 
+func parseVersion(t *testing.T, s string) conduitversion.Version {
+	t.Helper()
+
+	v, err := conduitversion.Parse(s)
+	require.NoError(t, err)
+
+	return v
+}
+
 func TestMigrator_MigrateUp(t *testing.T) {
 	t.Parallel()
 
@@ -405,3 +419,389 @@ func TestMigrator_Migrate_Result(t *testing.T) {
 	assert.Equal(t, "create_b", downResults[1].Name)
 	assert.Equal(t, "create_a", downResults[2].Name)
 }
+
+func TestMigrator_Migrate_GoMigrations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply Go and SQL migrations in version order", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql":   "CREATE TABLE users (id INT, email TEXT);",
+			"20230601120000_create_users.down.sql": "DROP TABLE users;",
+		})
+		require.NoError(t, r.Register(
+			conduitversion.NewFromTime(time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)),
+			"backfill_users",
+			func(ctx context.Context, conn *pgx.Conn) error {
+				_, err := conn.Exec(ctx, "INSERT INTO users (id, email) VALUES (1, 'a@example.com')")
+				return err //nolint:wrapcheck
+			},
+			func(ctx context.Context, conn *pgx.Conn) error {
+				_, err := conn.Exec(ctx, "DELETE FROM users")
+				return err //nolint:wrapcheck
+			},
+		))
+		require.NoError(t, r.RegisterTx(
+			conduitversion.NewFromTime(time.Date(2023, 6, 3, 12, 0, 0, 0, time.UTC)),
+			"create_posts",
+			func(ctx context.Context, tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "CREATE TABLE posts (id INT)")
+				return err //nolint:wrapcheck
+			},
+			func(ctx context.Context, tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "DROP TABLE posts")
+				return err //nolint:wrapcheck
+			},
+		))
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 3)
+		assert.Equal(t, "create_users", results[0].Name)
+		assert.Equal(t, "backfill_users", results[1].Name)
+		assert.Equal(t, "create_posts", results[2].Name)
+		assert.True(t, testutil.TableExists(t, pool, "posts"))
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_users"},
+			{Version: "20230602120000", Name: "backfill_users"},
+			{Version: "20230603120000", Name: "create_posts"},
+		}, appliedMigrations(t, pool))
+
+		// Act — roll back the Go migrations
+		seq, err = m.Migrate(t.Context(), conduit.DirectionDown, conn, &conduit.MigrateOptions{Steps: 2})
+
+		// Assert
+		require.NoError(t, err)
+		results = testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.False(t, testutil.TableExists(t, pool, "posts"))
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_users"},
+		}, appliedMigrations(t, pool))
+	})
+}
+
+func TestMigrator_Migrate_Namespaces(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply migrations from composed registries, when versions collide", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+
+		shieldFS, _, shieldDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_init.up.sql", "CREATE TABLE shield_users (id INT);").
+			WithFile("20230601120000_init.down.sql", "DROP TABLE shield_users;").
+			Build()
+		appFS, _, appDir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_init.up.sql", "CREATE TABLE app_posts (id INT);").
+			WithFile("20230601120000_init.down.sql", "DROP TABLE app_posts;").
+			Build()
+
+		r, err := conduitregistry.Compose(
+			conduitregistry.FromFS(shieldFS, shieldDir, conduitregistry.WithNamespace("shield")),
+			conduitregistry.FromFS(appFS, appDir, conduitregistry.WithNamespace("app")),
+		)
+		require.NoError(t, err)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(r), conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.Equal(t, "app/20230601120000_init", results[0].Key())
+		assert.Equal(t, "shield/20230601120000_init", results[1].Key())
+		assert.True(t, testutil.TableExists(t, pool, "shield_users"))
+		assert.True(t, testutil.TableExists(t, pool, "app_posts"))
+
+		// Act — nothing is pending on a second run
+		seq, err = m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, testutil.CollectSeq2(t, seq))
+	})
+}
+
+func TestMigrator_Migrate_HistoryTable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep histories apart, when migrators use different tables", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		testutil.Exec(t, pool, string(migrations.SchemaFor(migrations.NewTable("billing", "history"))))
+
+		r := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_invoices.up.sql":   "CREATE TABLE invoices (id INT);",
+			"20230601120000_create_invoices.down.sql": "DROP TABLE invoices;",
+		})
+		billing := conduit.NewMigrator(
+			conduit.WithRegistry(r),
+			conduit.WithHistorySchema("billing"),
+			conduit.WithHistoryTable("history"),
+			conduit.WithSkipSchemaDriftCheck(),
+		)
+
+		// Act
+		seq, err := billing.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, testutil.CollectSeq2(t, seq), 1)
+		assert.True(t, testutil.TableExists(t, pool, "invoices"))
+		assert.Empty(t, appliedMigrations(t, pool))
+
+		rows, err := dbsqlc.New().TestAllMigrations(
+			t.Context(),
+			dbsqlc.WithTable(pool, migrations.NewTable("billing", "history")),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_invoices"},
+		}, rows)
+	})
+}
+
+func TestMigrator_Migrate_Target(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql":   "CREATE TABLE a (id INT);",
+		"20230601120000_create_a.down.sql": "DROP TABLE a;",
+		"20230602120000_create_b.up.sql":   "CREATE TABLE b (id INT);",
+		"20230602120000_create_b.down.sql": "DROP TABLE b;",
+		"20230603120000_create_c.up.sql":   "CREATE TABLE c (id INT);",
+		"20230603120000_create_c.down.sql": "DROP TABLE c;",
+	}
+
+	t.Run("should apply migrations up to target, when target version is ahead", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		to, err := conduitversion.Parse("20230602120000")
+		require.NoError(t, err)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), "", conn, &conduit.MigrateOptions{To: to})
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.Equal(t, conduit.DirectionUp, results[1].Direction)
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_a"},
+			{Version: "20230602120000", Name: "create_b"},
+		}, appliedMigrations(t, pool))
+	})
+
+	t.Run("should roll back newer migrations, when target version is behind", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		to, err := conduitversion.Parse("20230601120000")
+		require.NoError(t, err)
+
+		// Act
+		seq, err = m.Migrate(t.Context(), "", conn, &conduit.MigrateOptions{To: to})
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 2)
+		assert.Equal(t, "create_c", results[0].Name)
+		assert.Equal(t, "create_b", results[1].Name)
+		assert.Equal(t, conduit.DirectionDown, results[1].Direction)
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_a"},
+		}, appliedMigrations(t, pool))
+	})
+
+	t.Run("should roll back to the state before a timestamp, when ToTime is set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		seq, err = m.Migrate(t.Context(), conduit.DirectionDown, conn, &conduit.MigrateOptions{
+			ToTime: time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, testutil.CollectSeq2(t, seq), 2)
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_a"},
+		}, appliedMigrations(t, pool))
+	})
+
+	t.Run("should return error, when target version is not in the registry", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		to, err := conduitversion.Parse("20230601130000")
+		require.NoError(t, err)
+
+		// Act
+		_, err = m.Migrate(t.Context(), "", conn, &conduit.MigrateOptions{To: to})
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrUnknownTarget)
+	})
+}
+
+func TestMigrator_Migrate_Repeatable(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_users.up.sql":   "CREATE TABLE users (id INT, active BOOLEAN);",
+		"20230601120000_create_users.down.sql": "DROP TABLE users;",
+		"R_active_users.sql":                   "CREATE OR REPLACE VIEW active_users AS SELECT id FROM users WHERE active;",
+	}
+
+	withFile := func(name, content string) map[string]string {
+		changed := maps.Clone(files)
+		changed[name] = content
+
+		return changed
+	}
+
+	t.Run("should apply repeatable migrations after versioned ones", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 2)
+		assert.Equal(t, "20230601120000_create_users", results[0].Key())
+		assert.Equal(t, "R_active_users", results[1].Key())
+	})
+
+	t.Run("should skip repeatable migrations, when content is unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		seq, err = m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		assert.Empty(t, results)
+	})
+
+	t.Run("should reapply repeatable migration, when content changed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		changed := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, withFile(
+			"R_active_users.sql",
+			"CREATE OR REPLACE VIEW active_users AS SELECT id, active FROM users WHERE active;",
+		))))
+
+		report, err := changed.Status(t.Context(), conn)
+		require.NoError(t, err)
+		require.Len(t, report.Pending(), 1)
+		assert.True(t, report.Pending()[0].Outdated())
+
+		// Act
+		seq, err = changed.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "R_active_users", results[0].Key())
+
+		var n int
+		require.NoError(t, conn.QueryRow(
+			t.Context(),
+			"SELECT count(*) FROM information_schema.columns WHERE table_name = 'active_users'",
+		).Scan(&n))
+		assert.Equal(t, 2, n)
+
+		report, err = changed.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Empty(t, report.Pending())
+	})
+
+	t.Run("should not roll back repeatable migrations", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, withFile(
+			"20230601120000_create_users.down.sql",
+			"DROP TABLE users CASCADE;",
+		))))
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		seq, err = m.Migrate(t.Context(), conduit.DirectionDown, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "20230601120000_create_users", results[0].Key())
+	})
+}
@@ -0,0 +1,42 @@
+package conduit
+
+import (
+	"time"
+
+	"go.inout.gg/conduit/conduitregistry"
+)
+
+// Observer receives measurements of the work a Migrator does, for example
+// to export them as metrics; see the conduitmetrics package for a
+// Prometheus implementation. Methods are called synchronously from the
+// migration run and must not block.
+type Observer interface {
+	// ObserveLock is called after each attempt to take the advisory lock
+	// with the time spent waiting for it and the error, if any.
+	ObserveLock(wait time.Duration, err error)
+
+	// ObserveDriftCheck is called after each schema drift check with the
+	// error it failed with, if any; [ErrSchemaDrift] when drift was found.
+	ObserveDriftCheck(err error)
+
+	// ObserveHazardsBlocked is called when migration is not run in
+	// direction dir because of hazards that were not allowed.
+	ObserveHazardsBlocked(migration *Migration, dir Direction, hazards []conduitregistry.Hazard)
+
+	// ObserveMigration is called after each migration the executor ran in
+	// direction dir, with the time it took and the error, if any.
+	ObserveMigration(migration *Migration, dir Direction, d time.Duration, err error)
+
+	// ObservePending is called with the number of pending migrations
+	// whenever a Migrator reads the history table: on [Migrator.Status],
+	// which every run calls, and at the end of every run.
+	ObservePending(n int)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) ObserveLock(time.Duration, error)                                      {}
+func (nopObserver) ObserveDriftCheck(error)                                               {}
+func (nopObserver) ObserveHazardsBlocked(*Migration, Direction, []conduitregistry.Hazard) {}
+func (nopObserver) ObserveMigration(*Migration, Direction, time.Duration, error)          {}
+func (nopObserver) ObservePending(int)                                                    {}
@@ -12,18 +12,74 @@ import (
 
 const format = "20060102150405" // YYYYMMDDHHMMSS
 
-// Version is a timestamp-based migration version using YYYYMMDDHHMMSS format.
-type Version struct{ t time.Time }
+// RepeatablePrefix is the version of repeatable migrations, both in
+// filenames (R_<name>.sql) and in the history table.
+const RepeatablePrefix = "R"
+
+// Version is a timestamp-based migration version using YYYYMMDDHHMMSS format,
+// or the version of a repeatable migration, see [Repeatable].
+type Version struct {
+	t          time.Time
+	repeatable bool
+}
 
 // NewFromTime creates a Version from the given time, truncating to second precision.
-func NewFromTime(t time.Time) Version { return Version{t: t} }
+func NewFromTime(t time.Time) Version { return Version{t: t, repeatable: false} }
+
+// Repeatable returns the version shared by all repeatable migrations, which
+// are reapplied whenever their content changes. It is newer than every
+// timestamp-based version, so repeatable migrations run last.
+func Repeatable() Version { return Version{t: time.Time{}, repeatable: true} }
+
+// Parse parses a YYYYMMDDHHMMSS string, or [RepeatablePrefix], into a Version.
+func Parse(s string) (Version, error) {
+	if s == RepeatablePrefix {
+		return Repeatable(), nil
+	}
+
+	t, err := time.Parse(format, s)
+	if err != nil {
+		return Version{}, fmt.Errorf(
+			"invalid version format %q, expected: YYYYMMDDHHMMSS: %w", s, err)
+	}
+
+	return Version{t: t, repeatable: false}, nil
+}
+
+// IsZero reports whether v is the zero Version.
+func (v Version) IsZero() bool { return !v.repeatable && v.t.IsZero() }
+
+// IsRepeatable reports whether v is the version of repeatable migrations.
+func (v Version) IsRepeatable() bool { return v.repeatable }
 
-// String formats the version as a YYYYMMDDHHMMSS string.
-func (v Version) String() string { return v.t.Format(format) }
+// Time returns the point in time the version represents. It is zero for
+// the repeatable version.
+func (v Version) Time() time.Time { return v.t }
+
+// String formats the version as a YYYYMMDDHHMMSS string, or as
+// [RepeatablePrefix] for the repeatable version.
+func (v Version) String() string {
+	if v.repeatable {
+		return RepeatablePrefix
+	}
+
+	return v.t.Format(format)
+}
 
 // Compare returns -1, 0, or 1 if v is older than, equal to, or newer than
-// other.
-func (v Version) Compare(other Version) int { return v.t.Compare(other.t) }
+// other. The repeatable version is newer than every other version.
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.repeatable && other.repeatable:
+		return 0
+	case v.repeatable:
+		return 1
+	case other.repeatable:
+		return -1
+	}
+
+	return v.t.Compare(other.t)
+}
 
 // MigrationDirection indicates whether a migration file is up-only or down-only.
 type MigrationDirection string
@@ -35,8 +91,13 @@ const (
 	MigrationDirectionDown MigrationDirection = "down"
 )
 
-// MigrationFilename generates a filename for a SQL migration file.
+// MigrationFilename generates a filename for a SQL migration file. Repeatable
+// migrations have no down file, and their filename no direction suffix.
 func MigrationFilename(v Version, name string, direction MigrationDirection) string {
+	if v.repeatable {
+		return fmt.Sprintf("%s_%s.sql", RepeatablePrefix, name)
+	}
+
 	return fmt.Sprintf("%s_%s.%s.sql", v.String(), name, direction)
 }
 
@@ -66,6 +127,8 @@ func (f ParsedMigrationFilename) Filename() string {
 // Supported formats:
 //   - <version>_<name>.up.sql — up migration
 //   - <version>_<name>.down.sql — down migration
+//   - R_<name>.sql — repeatable migration, parsed as an up migration of
+//     the [Repeatable] version
 func ParseMigrationFilename(filename string) (ParsedMigrationFilename, error) {
 	var m ParsedMigrationFilename
 
@@ -83,6 +146,10 @@ func ParseMigrationFilename(filename string) (ParsedMigrationFilename, error) {
 	// Check for direction suffix (.up.sql or .down.sql).
 	withoutExt := strings.TrimSuffix(basename, ext)
 
+	if name, ok := strings.CutPrefix(withoutExt, RepeatablePrefix+"_"); ok {
+		return parseRepeatableFilename(basename, name)
+	}
+
 	var direction MigrationDirection
 
 	switch {
@@ -105,17 +172,45 @@ func ParseMigrationFilename(filename string) (ParsedMigrationFilename, error) {
 		)
 	}
 
-	ver, err := time.Parse(format, version)
+	ver, err := Parse(version)
 	if err != nil {
-		return m, fmt.Errorf(
-			"invalid version format %q, expected: YYYYMMDDHHMMSS: %w", version, err)
+		return m, err
 	}
 
 	m = ParsedMigrationFilename{
-		Version:   Version{ver},
+		Version:   ver,
 		Name:      name,
 		Direction: direction,
 	}
 
 	return m, nil
 }
+
+// parseRepeatableFilename parses the name part of a R_<name>.sql filename.
+func parseRepeatableFilename(basename, name string) (ParsedMigrationFilename, error) {
+	var m ParsedMigrationFilename
+
+	if strings.HasSuffix(name, ".up") || strings.HasSuffix(name, ".down") {
+		return m, fmt.Errorf(
+			"repeatable migration file %q must not have .up.sql or .down.sql suffix, expected: %s_<name>.sql",
+			basename,
+			RepeatablePrefix,
+		)
+	}
+
+	if name == "" {
+		return m, fmt.Errorf(
+			"malformed repeatable migration filename, expected: %s_<name>.sql, got: %s",
+			RepeatablePrefix,
+			basename,
+		)
+	}
+
+	m = ParsedMigrationFilename{
+		Version:   Repeatable(),
+		Name:      name,
+		Direction: MigrationDirectionUp,
+	}
+
+	return m, nil
+}
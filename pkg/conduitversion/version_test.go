@@ -42,6 +42,24 @@ func TestVersion_Compare(t *testing.T) {
 		// Assert
 		assert.Equal(t, 0, result)
 	})
+
+	t.Run("should order repeatable version after every other version", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		v := conduitversion.NewFromTime(parseTime("99991231235959"))
+		r := conduitversion.Repeatable()
+
+		// Act
+		result1 := r.Compare(v)
+		result2 := v.Compare(r)
+		result3 := r.Compare(conduitversion.Repeatable())
+
+		// Assert
+		assert.Equal(t, 1, result1)
+		assert.Equal(t, -1, result2)
+		assert.Equal(t, 0, result3)
+	})
 }
 
 func TestParseMigrationFilename(t *testing.T) {
@@ -101,6 +119,16 @@ func TestParseMigrationFilename(t *testing.T) {
 			filename:       "",
 			expectedErrMsg: "filename cannot be empty",
 		},
+		{
+			name:           "Repeatable migration with direction suffix",
+			filename:       "R_users_view.up.sql",
+			expectedErrMsg: `repeatable migration file "R_users_view.up.sql" must not have .up.sql or .down.sql suffix`,
+		},
+		{
+			name:           "Repeatable migration without name",
+			filename:       "R_.sql",
+			expectedErrMsg: "malformed repeatable migration filename, expected: R_<name>.sql, got: R_.sql",
+		},
 		{
 			name:           "Invalid conduitversion format",
 			filename:       "1234_invalid_conduitversion.up.sql",
@@ -178,6 +206,24 @@ func TestParsedMigrationFilename_Filename(t *testing.T) {
 	})
 }
 
+func TestParseMigrationFilename_Repeatable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse repeatable up migration, when filename has R prefix", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		parsed, err := conduitversion.ParseMigrationFilename("/migrations/R_users_view.sql")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, parsed.Version.IsRepeatable())
+		assert.Equal(t, "users_view", parsed.Name)
+		assert.Equal(t, conduitversion.MigrationDirectionUp, parsed.Direction)
+		assert.Equal(t, "R_users_view.sql", parsed.Filename())
+	})
+}
+
 // parseTime is helper function to parse time in the expected format.
 func parseTime(timeStr string) time.Time {
 	t, err := time.Parse("20060102150405", timeStr)
@@ -187,3 +233,42 @@ func parseTime(timeStr string) time.Time {
 
 	return t
 }
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should parse version, when string is in YYYYMMDDHHMMSS format", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		v, err := conduitversion.Parse("20230601120000")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "20230601120000", v.String())
+		assert.False(t, v.IsZero())
+	})
+
+	t.Run("should parse repeatable version, when string is R", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		v, err := conduitversion.Parse("R")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, v.IsRepeatable())
+		assert.Equal(t, "R", v.String())
+		assert.False(t, v.IsZero())
+	})
+
+	t.Run("should return error, when string is malformed", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		_, err := conduitversion.Parse("2023-06-01")
+
+		// Assert
+		require.ErrorContains(t, err, `invalid version format "2023-06-01", expected: YYYYMMDDHHMMSS`)
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
@@ -13,13 +12,58 @@ import (
 	schemadiff "github.com/stripe/pg-schema-diff/pkg/diff"
 	"github.com/stripe/pg-schema-diff/pkg/schema"
 	"github.com/stripe/pg-schema-diff/pkg/tempdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.inout.gg/conduit/internal/migrationfile"
 	"go.inout.gg/conduit/internal/migrations"
 	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/tracing"
 	"go.inout.gg/conduit/pkg/sqlsplit"
 )
 
+// Option configures schema diff operations.
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	templateVars   map[string]any
+	table          migrations.Table
+}
+
+// WithHistoryTable sets the schema and name of conduit's history table, so
+// that it is treated as conduit-managed state rather than user schema.
+// Defaults to conduit_migrations resolved through search_path.
+func WithHistoryTable(schema, name string) Option {
+	return func(o *options) { o.table = migrations.NewTable(schema, name) }
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider spans are
+// created with. Defaults to the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) { o.tracerProvider = tp }
+}
+
+// WithTemplateVars sets the variables the templated migrations of the
+// migrations directory are rendered with, as conduitregistry.WithTemplateVars
+// does for a registry.
+func WithTemplateVars(vars map[string]any) Option {
+	return func(o *options) { o.templateVars = vars }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		tracerProvider: nil,
+		templateVars:   nil,
+		table:          migrations.NewTable("", migrations.DefaultTable),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
 // Plan holds the generated migration plan and the target schema hash.
 type Plan struct {
 	SourceSchemaHash string
@@ -36,10 +80,22 @@ func GeneratePlan(
 	connConfig *pgx.ConnConfig,
 	migrationsDir, schemaPath string,
 	excludeSchemas []string,
-) (Plan, error) {
-	var result Plan
-
-	sourceStmts, err := migrationfile.ReadStmtsFromDir(fs, migrationsDir)
+	opts ...Option,
+) (result Plan, err error) {
+	o := newOptions(opts)
+
+	ctx, span := tracing.Tracer(o.tracerProvider).Start(ctx, "conduit.pgdiff.generate_plan")
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("conduit.pgdiff.statements", len(result.Statements)),
+			attribute.String("conduit.pgdiff.target_schema_hash", result.TargetSchemaHash),
+		)
+		tracing.End(span, err)
+	}()
+
+	internalSchema := migrations.SchemaFor(o.table)
+
+	sourceStmts, err := migrationfile.ReadStmtsFromDir(fs, migrationsDir, o.templateVars)
 	if err != nil {
 		return result, fmt.Errorf("failed to read migrations: %w", err)
 	}
@@ -73,7 +129,7 @@ func GeneratePlan(
 
 	// Apply conduit's internal schema (e.g. conduit_migrations table) to the
 	// target database so the schema hash includes it.
-	if err := exec(ctx, targetDb.ConnPool, string(migrations.Schema)); err != nil {
+	if err := exec(ctx, targetDb.ConnPool, string(internalSchema)); err != nil {
 		return result, fmt.Errorf("failed to execute conduit internal schema: %w", err)
 	}
 
@@ -90,7 +146,7 @@ func GeneratePlan(
 
 	// Include conduit's internal schema in the source DDL so it matches the
 	// target and cancels out in the diff — only user schema changes remain.
-	internalStmts, err := sqlsplit.Split(migrations.Schema)
+	internalStmts, err := sqlsplit.Split(internalSchema)
 	if err != nil {
 		return result, fmt.Errorf("failed to parse conduit internal schema: %w", err)
 	}
@@ -175,7 +231,16 @@ func DumpSchema(
 	ctx context.Context,
 	connConfig *pgx.ConnConfig,
 	excludeSchemas []string,
-) ([]schemadiff.Statement, error) {
+	opts ...Option,
+) (stmts []schemadiff.Statement, err error) {
+	o := newOptions(opts)
+
+	ctx, span := tracing.Tracer(o.tracerProvider).Start(ctx, "conduit.pgdiff.dump_schema")
+	defer func() {
+		span.SetAttributes(attribute.Int("conduit.pgdiff.statements", len(stmts)))
+		tracing.End(span, err)
+	}()
+
 	remoteDB := stdlib.OpenDB(*connConfig)
 	defer remoteDB.Close()
 
@@ -185,8 +250,21 @@ func DumpSchema(
 	}
 	defer factory.Close()
 
+	// Use conduit's internal schema as the baseline so that conduit-managed
+	// tables (e.g. conduit_migrations) cancel out in the diff against the remote DB.
+	internalDb, err := factory.Create(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create internal temp db: %w", err)
+	}
+	defer internalDb.Close(ctx)
+
+	if err := exec(ctx, internalDb.ConnPool, string(migrations.SchemaFor(o.table))); err != nil {
+		return nil, fmt.Errorf("failed to execute conduit internal schema: %w", err)
+	}
+
 	planOpts := []schemadiff.PlanOpt{
 		schemadiff.WithTempDbFactory(factory),
+		schemadiff.WithGetSchemaOpts(internalDb.ExcludeMetadataOptions...),
 		schemadiff.WithDoNotValidatePlan(),
 		schemadiff.WithNoConcurrentIndexOps(),
 	}
@@ -194,21 +272,9 @@ func DumpSchema(
 		planOpts = append(planOpts, schemadiff.WithExcludeSchemas(excludeSchemas...))
 	}
 
-	// Use conduit's internal schema as the DDL baseline so that conduit-managed
-	// tables (e.g. conduit_migrations) cancel out in the diff against the remote DB.
-	internalStmts, err := sqlsplit.Split(migrations.Schema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse conduit internal schema: %w", err)
-	}
-
 	plan, err := schemadiff.Generate(
 		ctx,
-		schemadiff.DDLSchemaSource(sliceutil.Map(
-			sliceutil.Filter(internalStmts, func(s sqlsplit.Stmt) bool {
-				return s.Type == sqlsplit.StmtTypeQuery
-			}),
-			func(s sqlsplit.Stmt) string { return s.Content },
-		)),
+		schemadiff.DBSchemaSource(internalDb.ConnPool),
 		schemadiff.DBSchemaSource(remoteDB),
 		planOpts...,
 	)
@@ -216,11 +282,14 @@ func DumpSchema(
 		return nil, fmt.Errorf("failed to dump schema: %w", err)
 	}
 
-	// Filter out any remaining conduit internal statements (e.g. when the
-	// remote DB doesn't have conduit tables yet).
-	return sliceutil.Filter(plan.Statements, func(s schemadiff.Statement) bool {
-		return !strings.Contains(s.DDL, "conduit_migrations")
-	}), nil
+	// Filter out any remaining statements on conduit's own tables (e.g. when
+	// the remote DB doesn't have them yet or has them in an older shape).
+	managed, err := managedObjects(ctx, o.table, remoteDB, internalDb.ConnPool)
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutManaged(plan.Statements, managed), nil
 }
 
 func newTempDbFactory(ctx context.Context, connConfig *pgx.ConnConfig) (tempdb.Factory, error) {
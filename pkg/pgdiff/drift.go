@@ -0,0 +1,291 @@
+package pgdiff
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	schemadiff "github.com/stripe/pg-schema-diff/pkg/diff"
+	"go.opentelemetry.io/otel/attribute"
+
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/tracing"
+	"go.inout.gg/conduit/pkg/sqlsplit"
+)
+
+// ObjectKind is the kind of a schema object that drifted.
+type ObjectKind string
+
+const (
+	ObjectKindColumn           ObjectKind = "column"
+	ObjectKindConstraint       ObjectKind = "constraint"
+	ObjectKindExtension        ObjectKind = "extension"
+	ObjectKindFunction         ObjectKind = "function"
+	ObjectKindIndex            ObjectKind = "index"
+	ObjectKindMaterializedView ObjectKind = "materialized view"
+	ObjectKindPolicy           ObjectKind = "policy"
+	ObjectKindProcedure        ObjectKind = "procedure"
+	ObjectKindSchema           ObjectKind = "schema"
+	ObjectKindSequence         ObjectKind = "sequence"
+	ObjectKindTable            ObjectKind = "table"
+	ObjectKindTrigger          ObjectKind = "trigger"
+	ObjectKindType             ObjectKind = "type"
+	ObjectKindView             ObjectKind = "view"
+	ObjectKindOther            ObjectKind = "other"
+)
+
+// ObjectChange describes how a drifted object differs from the expected
+// schema.
+type ObjectChange string
+
+const (
+	// ObjectChangeMissing marks an object the migrations create that the
+	// database does not have.
+	ObjectChangeMissing ObjectChange = "missing"
+
+	// ObjectChangeUnexpected marks an object the database has that the
+	// migrations do not create.
+	ObjectChangeUnexpected ObjectChange = "unexpected"
+
+	// ObjectChangeModified marks an object that exists on both sides but
+	// differs. Objects reconciled with CREATE OR REPLACE, such as functions
+	// and views, are reported as modified even when they are missing.
+	ObjectChangeModified ObjectChange = "modified"
+)
+
+// DriftedObject is a schema object that differs between the database and
+// the schema its migrations describe.
+type DriftedObject struct {
+	// Kind is the kind of the object.
+	Kind ObjectKind
+
+	// Name is the schema-qualified name of the object; columns and
+	// constraints are qualified with their table. It is empty for
+	// [ObjectKindOther].
+	Name string
+
+	// Change is how the object differs.
+	Change ObjectChange
+}
+
+// DriftReport lists the differences between a live database and the schema
+// its migrations describe.
+type DriftReport struct {
+	// Objects lists each drifted object once, in the order of Statements.
+	Objects []DriftedObject
+
+	// Statements is the DDL that reconciles the database with the expected
+	// schema.
+	Statements []schemadiff.Statement
+}
+
+// HasDrift reports whether the database differs from the expected schema.
+func (r *DriftReport) HasDrift() bool { return len(r.Statements) > 0 }
+
+// Drift compares the schema of the live database connConfig points to with
+// the schema built by running expected, the statements of the applied
+// migrations, after conduit's internal schema in a temporary database on the
+// same instance. Conduit's history and log tables, see [WithHistoryTable],
+// are left out of the comparison.
+func Drift(
+	ctx context.Context,
+	connConfig *pgx.ConnConfig,
+	expected []sqlsplit.Stmt,
+	excludeSchemas []string,
+	opts ...Option,
+) (report DriftReport, err error) {
+	o := newOptions(opts)
+
+	ctx, span := tracing.Tracer(o.tracerProvider).Start(ctx, "conduit.pgdiff.drift")
+	defer func() {
+		span.SetAttributes(attribute.Int("conduit.pgdiff.statements", len(report.Statements)))
+		tracing.End(span, err)
+	}()
+
+	factory, err := newTempDbFactory(ctx, connConfig)
+	if err != nil {
+		return report, err
+	}
+	defer factory.Close()
+
+	expectedDb, err := factory.Create(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to create expected temp db: %w", err)
+	}
+	defer expectedDb.Close(ctx)
+
+	if err := exec(ctx, expectedDb.ConnPool, string(migrations.SchemaFor(o.table))); err != nil {
+		return report, fmt.Errorf("failed to execute conduit internal schema: %w", err)
+	}
+
+	for _, stmt := range expected {
+		if stmt.Type != sqlsplit.StmtTypeQuery {
+			continue
+		}
+
+		if _, err := expectedDb.ConnPool.ExecContext(ctx, stmt.Content); err != nil {
+			return report, fmt.Errorf("failed to execute migration statement: %w", err)
+		}
+	}
+
+	liveDB := stdlib.OpenDB(*connConfig)
+	defer liveDB.Close()
+
+	planOpts := []schemadiff.PlanOpt{
+		schemadiff.WithTempDbFactory(factory),
+		schemadiff.WithGetSchemaOpts(expectedDb.ExcludeMetadataOptions...),
+		schemadiff.WithDoNotValidatePlan(),
+	}
+	if len(excludeSchemas) > 0 {
+		planOpts = append(planOpts, schemadiff.WithExcludeSchemas(excludeSchemas...))
+	}
+
+	plan, err := schemadiff.Generate(
+		ctx,
+		schemadiff.DBSchemaSource(liveDB),
+		schemadiff.DBSchemaSource(expectedDb.ConnPool),
+		planOpts...,
+	)
+	if err != nil {
+		return report, fmt.Errorf("failed to generate drift plan: %w", err)
+	}
+
+	// Conduit's own tables are not described by the migrations: a database
+	// whose tables were created by an earlier version of conduit differs
+	// from conduit's internal schema without having drifted.
+	managed, err := managedObjects(ctx, o.table, liveDB, expectedDb.ConnPool)
+	if err != nil {
+		return report, err
+	}
+
+	report.Statements = withoutManaged(plan.Statements, managed)
+	report.Objects = driftedObjects(report.Statements)
+
+	return report, nil
+}
+
+// objectPattern classifies a DDL statement. The first matching pattern
+// wins: the name group is the object name and the table group, when
+// present, the table it qualifies.
+type objectPattern struct {
+	re     *regexp.Regexp
+	kind   ObjectKind // empty to take the kind from the kind group
+	change ObjectChange
+}
+
+const ident = `((?:"(?:[^"]|"")*"|[^\s"(.]+)(?:\.(?:"(?:[^"]|"")*"|[^\s"(.]+))*)`
+
+//nolint:gochecknoglobals
+var objectPatterns = []objectPattern{
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) ADD COLUMN (?P<name>` + ident + `)`), ObjectKindColumn, ObjectChangeMissing},
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) DROP COLUMN (?P<name>` + ident + `)`), ObjectKindColumn, ObjectChangeUnexpected},
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) ALTER COLUMN (?P<name>` + ident + `)`), ObjectKindColumn, ObjectChangeModified},
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) ADD CONSTRAINT (?P<name>` + ident + `)`), ObjectKindConstraint, ObjectChangeMissing},
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) DROP CONSTRAINT (?P<name>` + ident + `)`), ObjectKindConstraint, ObjectChangeUnexpected},
+	{regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(?P<table>` + ident + `) VALIDATE CONSTRAINT (?P<name>` + ident + `)`), ObjectKindConstraint, ObjectChangeModified},
+	{regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (?:CONCURRENTLY )?(?:IF NOT EXISTS )?(?P<name>` + ident + `) ON (?:ONLY )?(?P<table>` + ident + `)`), ObjectKindIndex, ObjectChangeMissing},
+	{regexp.MustCompile(`(?s)^(?:CREATE|DROP) TRIGGER (?P<name>` + ident + `) .*?\bON (?P<table>` + ident + `)`), ObjectKindTrigger, ""},
+	{regexp.MustCompile(`^(?:CREATE|DROP|ALTER) POLICY (?P<name>` + ident + `) ON (?P<table>` + ident + `)`), ObjectKindPolicy, ""},
+	{regexp.MustCompile(`^(?:CREATE(?: OR REPLACE)?|DROP|ALTER) (?P<kind>TABLE|INDEX|SEQUENCE|FUNCTION|PROCEDURE|VIEW|MATERIALIZED VIEW|SCHEMA|EXTENSION|TYPE)(?: CONCURRENTLY)?(?: IF (?:NOT )?EXISTS)? (?P<name>` + ident + `)`), "", ""},
+}
+
+// driftedObjects returns the objects stmts change, each once.
+func driftedObjects(stmts []schemadiff.Statement) []DriftedObject {
+	var objects []DriftedObject
+
+	for _, stmt := range stmts {
+		object := classifyStatement(stmt.DDL)
+		if object.Kind != ObjectKindOther && slices.ContainsFunc(objects, func(o DriftedObject) bool {
+			return o.Kind == object.Kind && o.Name == object.Name
+		}) {
+			continue
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects
+}
+
+// classifyStatement returns the object a DDL statement generated by
+// pg-schema-diff changes.
+func classifyStatement(ddl string) DriftedObject {
+	object, _ := classify(ddl)
+
+	return object
+}
+
+// classify returns the object a DDL statement generated by pg-schema-diff
+// changes and, for objects that belong to a table, the schema-qualified name
+// of the table.
+func classify(ddl string) (DriftedObject, string) {
+	ddl = strings.TrimSpace(ddl)
+
+	for _, p := range objectPatterns {
+		m := p.re.FindStringSubmatch(ddl)
+		if m == nil {
+			continue
+		}
+
+		object := DriftedObject{Kind: p.kind, Name: "", Change: p.change}
+
+		var table string
+
+		for i, group := range p.re.SubexpNames() {
+			switch group {
+			case "kind":
+				object.Kind = ObjectKind(strings.ToLower(m[i]))
+			case "name":
+				object.Name = unquote(m[i])
+			case "table":
+				table = unquote(m[i])
+			}
+		}
+
+		switch object.Kind {
+		case ObjectKindColumn, ObjectKindConstraint, ObjectKindTrigger, ObjectKindPolicy:
+			object.Name = table + "." + object.Name
+		case ObjectKindIndex:
+			// Indexes are created with an unqualified name in the schema of
+			// their table.
+			if schema, _, ok := strings.Cut(table, "."); ok && !strings.Contains(object.Name, ".") {
+				object.Name = schema + "." + object.Name
+			}
+		}
+
+		if object.Change == "" {
+			object.Change = statementChange(ddl)
+		}
+
+		return object, table
+	}
+
+	return DriftedObject{Kind: ObjectKindOther, Name: "", Change: ObjectChangeModified}, ""
+}
+
+// statementChange returns the change a CREATE, DROP or ALTER statement
+// makes.
+func statementChange(ddl string) ObjectChange {
+	switch {
+	case strings.HasPrefix(ddl, "CREATE OR REPLACE "):
+		return ObjectChangeModified
+	case strings.HasPrefix(ddl, "CREATE "):
+		return ObjectChangeMissing
+	case strings.HasPrefix(ddl, "DROP "):
+		return ObjectChangeUnexpected
+	default:
+		return ObjectChangeModified
+	}
+}
+
+// unquote strips identifier quotes from a possibly qualified name.
+func unquote(name string) string {
+	name = strings.ReplaceAll(name, `""`, "\x00")
+	name = strings.ReplaceAll(name, `"`, "")
+
+	return strings.ReplaceAll(name, "\x00", `"`)
+}
@@ -0,0 +1,140 @@
+package pgdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	schemadiff "github.com/stripe/pg-schema-diff/pkg/diff"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ddl  string
+		want DriftedObject
+	}{
+		{
+			ddl:  "CREATE TABLE \"public\".\"posts\" (\n\t\"id\" integer\n)",
+			want: DriftedObject{Kind: ObjectKindTable, Name: "public.posts", Change: ObjectChangeMissing},
+		},
+		{
+			ddl:  `DROP TABLE "public"."posts"`,
+			want: DriftedObject{Kind: ObjectKindTable, Name: "public.posts", Change: ObjectChangeUnexpected},
+		},
+		{
+			ddl:  `ALTER TABLE "public"."users" ADD COLUMN "email" text COLLATE "pg_catalog"."default"`,
+			want: DriftedObject{Kind: ObjectKindColumn, Name: "public.users.email", Change: ObjectChangeMissing},
+		},
+		{
+			ddl:  `ALTER TABLE "public"."users" DROP COLUMN "email"`,
+			want: DriftedObject{Kind: ObjectKindColumn, Name: "public.users.email", Change: ObjectChangeUnexpected},
+		},
+		{
+			ddl:  `ALTER TABLE "public"."users" ALTER COLUMN "id" SET NOT NULL`,
+			want: DriftedObject{Kind: ObjectKindColumn, Name: "public.users.id", Change: ObjectChangeModified},
+		},
+		{
+			ddl:  `ALTER TABLE "public"."posts" ADD CONSTRAINT "posts_user_id_fkey" FOREIGN KEY (user_id) REFERENCES users(id) NOT VALID`,
+			want: DriftedObject{Kind: ObjectKindConstraint, Name: "public.posts.posts_user_id_fkey", Change: ObjectChangeMissing},
+		},
+		{
+			ddl:  `CREATE UNIQUE INDEX CONCURRENTLY users_email_idx ON "public"."users" USING btree (email)`,
+			want: DriftedObject{Kind: ObjectKindIndex, Name: "public.users_email_idx", Change: ObjectChangeMissing},
+		},
+		{
+			ddl:  `DROP INDEX CONCURRENTLY "public"."users_email_idx"`,
+			want: DriftedObject{Kind: ObjectKindIndex, Name: "public.users_email_idx", Change: ObjectChangeUnexpected},
+		},
+		{
+			ddl:  "CREATE OR REPLACE FUNCTION public.add(a integer, b integer)\n RETURNS integer",
+			want: DriftedObject{Kind: ObjectKindFunction, Name: "public.add", Change: ObjectChangeModified},
+		},
+		{
+			ddl:  "CREATE TRIGGER \"audit\" AFTER INSERT\n ON \"public\".\"users\" FOR EACH ROW EXECUTE FUNCTION audit()",
+			want: DriftedObject{Kind: ObjectKindTrigger, Name: "public.users.audit", Change: ObjectChangeMissing},
+		},
+		{
+			ddl:  `DROP MATERIALIZED VIEW "public"."stats"`,
+			want: DriftedObject{Kind: ObjectKindMaterializedView, Name: "public.stats", Change: ObjectChangeUnexpected},
+		},
+		{
+			ddl:  `ALTER TYPE "public"."mood" ADD VALUE 'meh'`,
+			want: DriftedObject{Kind: ObjectKindType, Name: "public.mood", Change: ObjectChangeModified},
+		},
+		{
+			ddl:  `ANALYZE "public"."users"`,
+			want: DriftedObject{Kind: ObjectKindOther, Name: "", Change: ObjectChangeModified},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ddl, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			got := classifyStatement(tt.ddl)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDriftedObjects(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should list each object once, when several statements change it", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		stmts := []schemadiff.Statement{
+			{DDL: `ALTER TABLE "public"."users" ALTER COLUMN "id" SET NOT NULL`},
+			{DDL: `ALTER TABLE "public"."users" ALTER COLUMN "id" SET DATA TYPE bigint`},
+			{DDL: `DROP TABLE "public"."posts"`},
+		}
+
+		// Act
+		objects := driftedObjects(stmts)
+
+		// Assert
+		assert.Equal(t, []DriftedObject{
+			{Kind: ObjectKindColumn, Name: "public.users.id", Change: ObjectChangeModified},
+			{Kind: ObjectKindTable, Name: "public.posts", Change: ObjectChangeUnexpected},
+		}, objects)
+	})
+}
+
+func TestWithoutManaged(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should drop statements on conduit tables, when they belong to them", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		managed := map[string]struct{}{
+			"public.conduit_migrations":            {},
+			"public.conduit_migrations_log":        {},
+			"public.conduit_migrations_log_id_seq": {},
+			"public.conduit_migrations_pkey":       {},
+		}
+		stmts := []schemadiff.Statement{
+			{DDL: "CREATE TABLE \"public\".\"conduit_migrations_log\" (\n\t\"id\" bigint\n)"},
+			{DDL: `CREATE SEQUENCE "public"."conduit_migrations_log_id_seq"`},
+			{DDL: `ALTER SEQUENCE "public"."conduit_migrations_log_id_seq" OWNED BY "public"."conduit_migrations_log"."id"`},
+			{DDL: `ALTER TABLE "public"."conduit_migrations" ADD COLUMN "checksum" character varying(64)`},
+			{DDL: `DROP INDEX CONCURRENTLY "public"."conduit_migrations_pkey"`},
+			{DDL: `CREATE TABLE "public"."conduit_migrations_archive" ("id" bigint)`},
+			{DDL: `ALTER TABLE "public"."users" ADD COLUMN "source" text DEFAULT 'conduit_migrations'`},
+		}
+
+		// Act
+		got := withoutManaged(stmts, managed)
+
+		// Assert
+		assert.Equal(t, []schemadiff.Statement{
+			{DDL: `CREATE TABLE "public"."conduit_migrations_archive" ("id" bigint)`},
+			{DDL: `ALTER TABLE "public"."users" ADD COLUMN "source" text DEFAULT 'conduit_migrations'`},
+		}, got)
+	})
+}
@@ -0,0 +1,98 @@
+package pgdiff
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	schemadiff "github.com/stripe/pg-schema-diff/pkg/diff"
+
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/sliceutil"
+)
+
+// managedObjectsQuery lists the schema-qualified names of the tables $1
+// names, of their indexes and of the sequences they own.
+const managedObjectsQuery = `
+WITH tables AS (
+  SELECT to_regclass(t) AS oid FROM unnest($1::TEXT[]) AS t
+)
+SELECT n.nspname || '.' || c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.oid IN (SELECT oid FROM tables)
+  OR c.oid IN (SELECT indexrelid FROM pg_index WHERE indrelid IN (SELECT oid FROM tables))
+  OR (c.relkind = 'S' AND c.oid IN (
+    SELECT objid FROM pg_depend
+    WHERE classid = 'pg_class'::REGCLASS
+      AND refclassid = 'pg_class'::REGCLASS
+      AND refobjid IN (SELECT oid FROM tables)
+  ))`
+
+// managedObjects returns the schema-qualified names of conduit's history
+// and log tables in each of dbs, along with those of their indexes and of
+// the sequences they own. A table missing from a database is named after
+// the schema it would be created in.
+func managedObjects(ctx context.Context, table migrations.Table, dbs ...*sql.DB) (map[string]struct{}, error) {
+	tables := []migrations.Table{table, table.Log()}
+	objects := make(map[string]struct{})
+
+	for _, db := range dbs {
+		var current string
+		if err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&current); err != nil {
+			return nil, fmt.Errorf("failed to fetch current schema: %w", err)
+		}
+
+		for _, t := range tables {
+			schema := t.Schema
+			if schema == "" {
+				schema = current
+			}
+
+			objects[schema+"."+t.Name] = struct{}{}
+		}
+
+		rows, err := db.QueryContext(
+			ctx,
+			managedObjectsQuery,
+			sliceutil.Map(tables, func(t migrations.Table) string { return t.String() }),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch conduit-managed objects: %w", err)
+		}
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				_ = rows.Close()
+
+				return nil, fmt.Errorf("failed to fetch conduit-managed objects: %w", err)
+			}
+
+			objects[name] = struct{}{}
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch conduit-managed objects: %w", err)
+		}
+	}
+
+	return objects, nil
+}
+
+// withoutManaged returns stmts without those that change one of the
+// conduit-managed objects, see managedObjects, or an object that belongs to
+// one of them.
+func withoutManaged(stmts []schemadiff.Statement, managed map[string]struct{}) []schemadiff.Statement {
+	return sliceutil.Filter(stmts, func(stmt schemadiff.Statement) bool {
+		object, table := classify(stmt.DDL)
+		if object.Kind == ObjectKindOther {
+			return true
+		}
+
+		_, ok := managed[object.Name]
+		_, tableOK := managed[table]
+
+		return !ok && !tableOK
+	})
+}
@@ -0,0 +1,151 @@
+package conduit
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"go.inout.gg/foundations/debug"
+
+	"go.inout.gg/conduit/internal/internaldebug"
+	"go.inout.gg/conduit/internal/tracing"
+)
+
+// Redo rolls back the latest applied migrations and applies them again,
+// under a single advisory lock and with a single schema drift check.
+//
+// opts.Steps sets how many migrations to redo and defaults to
+// [DefaultDownStep]; [AllSteps] redoes every applied migration.
+// opts.AllowHazards applies to both directions, and hazards are checked
+// for both before anything is rolled back. Targets are not supported:
+// setting To or ToTime yields [ErrInvalidTarget].
+//
+// The returned iterator yields the rolled back migrations first, newest
+// first, followed by the reapplied ones, oldest first; [MigrationResult]
+// Direction tells the halves apart. As with [Migrator.Migrate], the lock is
+// held until the iteration ends.
+func (m *Migrator) Redo(
+	ctx context.Context,
+	db DB,
+	opts *MigrateOptions,
+) (iter.Seq2[*MigrationResult, error], error) {
+	debug.Assert(db != nil, "expected db to be defined")
+
+	if opts == nil {
+		opts = new(MigrateOptions)
+
+		internaldebug.Log("opts is omitted, create a new one")
+	}
+
+	if _, ok := opts.target(); ok {
+		return nil, fmt.Errorf("%w: Redo does not support To or ToTime", ErrInvalidTarget)
+	}
+
+	opts.defaults(DirectionDown)
+
+	ctx, span := m.tracer.Start(ctx, "conduit.redo")
+
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		tracing.End(span, err)
+
+		return nil, err
+	}
+
+	ctx = s.context(ctx)
+
+	if err := m.lockHistory(ctx, s); err != nil {
+		s.close()
+		tracing.End(span, err)
+
+		return nil, err
+	}
+
+	down, up, err := m.planRedo(ctx, s.conn, opts)
+	if err != nil {
+		m.unlock(ctx, s)
+		tracing.End(span, err)
+
+		return nil, err
+	}
+
+	span.SetAttributes(attrMigrations.Int(len(down)))
+
+	downSeq := m.applyMigrations(ctx, down, DirectionDown, s.conn, opts)
+	upSeq := m.applyMigrations(ctx, up, DirectionUp, s.conn, opts)
+
+	return m.withLock(ctx, s, traceSeq(span, func(yield func(*MigrationResult, error) bool) {
+		for result, err := range downSeq {
+			if !yield(result, err) || err != nil {
+				return
+			}
+		}
+
+		for result, err := range upSeq {
+			if !yield(result, err) {
+				return
+			}
+		}
+	})), nil
+}
+
+// planRedo returns the migrations to roll back and to reapply. It must be
+// called with the advisory lock held.
+func (m *Migrator) planRedo(
+	ctx context.Context,
+	conn *pgx.Conn,
+	opts *MigrateOptions,
+) ([]*Migration, []*Migration, error) {
+	report, err := m.Status(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkDirty(report); err != nil {
+		return nil, nil, err
+	}
+
+	if !m.skipSchemaDriftCheck {
+		if err := m.detectSchemaDrift(ctx, conn); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	down, err := m.downMigrations(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Steps != AllSteps {
+		down = down[0:min(opts.Steps, len(down))]
+	}
+
+	if err := verifyChecksums(report); err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.checkConsistency(ctx, report, DirectionDown, down); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkDependencies(report, DirectionDown, down); err != nil {
+		return nil, nil, err
+	}
+
+	up := slices.Clone(down)
+	slices.Reverse(up)
+
+	for _, migration := range down {
+		if err := m.checkHazards(migration, DirectionDown, opts.AllowHazards); err != nil {
+			return nil, nil, err
+		}
+
+		if err := m.checkHazards(migration, DirectionUp, opts.AllowHazards); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return down, up, nil
+}
@@ -0,0 +1,71 @@
+package conduit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Redo(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql":   "CREATE TABLE a (id INT);",
+		"20230601120000_create_a.down.sql": "DROP TABLE a;",
+		"20230602120000_create_b.up.sql":   "CREATE TABLE b (id INT);",
+		"20230602120000_create_b.down.sql": "DROP TABLE b;",
+		"20230603120000_create_c.up.sql":   "CREATE TABLE c (id INT);",
+		"20230603120000_create_c.down.sql": "DROP TABLE c;",
+	}
+
+	t.Run("should roll back and reapply latest migrations, when steps is set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		seq, err = m.Redo(t.Context(), conn, &conduit.MigrateOptions{Steps: 2})
+
+		// Assert
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+		require.Len(t, results, 4)
+		assert.Equal(t, []string{
+			"down 20230603120000_create_c",
+			"down 20230602120000_create_b",
+			"up 20230602120000_create_b",
+			"up 20230603120000_create_c",
+		}, sliceutil.Map(results, func(r *conduit.MigrationResult) string {
+			return string(r.Direction) + " " + r.Key()
+		}))
+		assert.Len(t, appliedMigrations(t, pool), 3)
+		assert.True(t, testutil.TableExists(t, pool, "c"))
+	})
+
+	t.Run("should return error, when target is set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		_, err := m.Redo(t.Context(), conn, &conduit.MigrateOptions{ToTime: time.Now()})
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrInvalidTarget)
+	})
+}
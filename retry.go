@@ -0,0 +1,61 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"go.inout.gg/conduit/conduitregistry"
+)
+
+// SQLSTATE codes of the errors a [RetryPolicy] retries.
+const (
+	pgCodeLockNotAvailable = "55P03" // lock_timeout elapsed
+	pgCodeDeadlockDetected = "40P01"
+)
+
+// MigrationAttempt describes an attempt at running a migration.
+type MigrationAttempt struct {
+	// Err is the error the attempt failed with, nil for the attempt that
+	// succeeded.
+	Err error
+
+	// Duration is how long the attempt took, not counting the backoff
+	// before it.
+	Duration time.Duration
+}
+
+type retryPolicyKey struct{}
+
+// withRetryPolicy returns ctx carrying the retry policy of migrations that
+// declare none.
+func withRetryPolicy(ctx context.Context, p RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, p)
+}
+
+// migrationRetryPolicy returns the retry policy migration runs with in
+// direction dir: the one it declares, or else the one of the run.
+func migrationRetryPolicy(
+	ctx context.Context,
+	migration *conduitregistry.Migration,
+	dir Direction,
+) RetryPolicy {
+	if p, ok := migration.RetryPolicy(dir); ok {
+		return p
+	}
+
+	p, _ := ctx.Value(retryPolicyKey{}).(RetryPolicy)
+
+	return p
+}
+
+// isRetryable reports whether err is a lock timeout or a deadlock, which
+// another attempt may not run into.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) &&
+		(pgErr.Code == pgCodeLockNotAvailable || pgErr.Code == pgCodeDeadlockDetected)
+}
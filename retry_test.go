@@ -0,0 +1,146 @@
+package conduit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Retry(t *testing.T) {
+	t.Parallel()
+
+	// lockTable holds an access exclusive lock on a new table, locked, until
+	// the returned function is called.
+	lockTable := func(t *testing.T, pool *pgxpool.Pool) func() {
+		t.Helper()
+
+		_, err := pool.Exec(t.Context(), "CREATE TABLE locked (id INT)")
+		require.NoError(t, err)
+
+		tx, err := pool.Begin(t.Context())
+		require.NoError(t, err)
+
+		_, err = tx.Exec(t.Context(), "LOCK TABLE locked IN ACCESS EXCLUSIVE MODE")
+		require.NoError(t, err)
+
+		release := func() { _ = tx.Rollback(context.Background()) }
+		t.Cleanup(release)
+
+		return release
+	}
+
+	newRegistry := func(t *testing.T, content string) *conduitregistry.Registry {
+		t.Helper()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_alter_locked.up.sql", content).
+			Build()
+
+		return conduitregistry.FromFS(fs, dir)
+	}
+
+	t.Run("should retry migration, when it fails on lock timeout", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		release := lockTable(t, pool)
+		time.AfterFunc(150*time.Millisecond, release)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t, `---- enable-tx ----
+---- lock-timeout: 50ms ----
+---- retry: 10 backoff=20ms ----
+ALTER TABLE locked ADD COLUMN name TEXT;`)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+
+		attempts := results[0].Attempts
+		require.Greater(t, len(attempts), 1)
+		require.NoError(t, attempts[len(attempts)-1].Err)
+
+		for _, attempt := range attempts[:len(attempts)-1] {
+			assert.ErrorContains(t, attempt.Err, "lock timeout")
+		}
+	})
+
+	t.Run("should retry migration, when default policy is set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		release := lockTable(t, pool)
+		time.AfterFunc(150*time.Millisecond, release)
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(newRegistry(t, `---- lock-timeout: 50ms ----
+ALTER TABLE locked ADD COLUMN name TEXT;`)),
+			conduit.WithRetryPolicy(conduit.RetryPolicy{MaxRetries: 10, Backoff: 20 * time.Millisecond}),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Greater(t, len(results[0].Attempts), 1)
+	})
+
+	t.Run("should return error after last attempt, when lock is never released", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		lockTable(t, pool)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t, `---- enable-tx ----
+---- lock-timeout: 20ms ----
+---- retry: 2 backoff=10ms ----
+ALTER TABLE locked ADD COLUMN name TEXT;`)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		err = testutil.CollectSeq2Error(t, seq)
+
+		// Assert
+		require.ErrorContains(t, err, "after 3 attempts")
+	})
+
+	t.Run("should not retry, when a statement of a migration outside a transaction succeeded", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		lockTable(t, pool)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t, `---- lock-timeout: 20ms ----
+---- retry: 2 backoff=10ms ----
+CREATE TABLE other (id INT);
+ALTER TABLE locked ADD COLUMN name TEXT;`)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		err = testutil.CollectSeq2Error(t, seq)
+
+		// Assert
+		require.ErrorContains(t, err, "lock timeout")
+		assert.NotContains(t, err.Error(), "attempts")
+	})
+}
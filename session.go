@@ -0,0 +1,100 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUnsupportedDB = errors.New("unsupported database handle")
+
+// DB is a database handle migrations can run through.
+//
+// Migrate and friends accept *pgx.Conn, *pgxpool.Pool, *pgxpool.Conn and
+// pgx.Tx, as well as any other DB that either hands out dedicated sessions
+// through an Acquire(context.Context) (*pgxpool.Conn, error) method, like
+// *pgxpool.Pool, or exposes its underlying session through a
+// Conn() *pgx.Conn method, like pgx.Tx. Other implementations yield
+// [ErrUnsupportedDB].
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	_ DB = (*pgx.Conn)(nil)
+	_ DB = (*pgxpool.Pool)(nil)
+	_ DB = (*pgxpool.Conn)(nil)
+	_ DB = (pgx.Tx)(nil)
+)
+
+type (
+	acquirer interface {
+		Acquire(ctx context.Context) (*pgxpool.Conn, error)
+	}
+
+	connHolder interface {
+		Conn() *pgx.Conn
+	}
+)
+
+// session is the single database session a migration run uses for the
+// advisory lock, the history table and non-transactional statements.
+type session struct {
+	conn    *pgx.Conn
+	tx      pgx.Tx // caller-supplied transaction, if any
+	release func()
+}
+
+// acquireSession resolves db to a dedicated session. The session must be
+// closed once the run is over.
+func acquireSession(ctx context.Context, db DB) (*session, error) {
+	switch db := db.(type) {
+	case *pgx.Conn:
+		return &session{conn: db, tx: nil, release: nil}, nil
+	case pgx.Tx:
+		return &session{conn: db.Conn(), tx: db, release: nil}, nil
+	case acquirer:
+		conn, err := db.Acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire a connection: %w", err)
+		}
+
+		return &session{conn: conn.Conn(), tx: nil, release: conn.Release}, nil
+	case connHolder:
+		return &session{conn: db.Conn(), tx: nil, release: nil}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedDB, db)
+}
+
+// context returns ctx carrying the caller-supplied transaction, if any, so
+// that the executor nests transactional migrations inside it.
+func (s *session) context(ctx context.Context) context.Context {
+	if s.tx == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, callerTxKey{}, s.tx)
+}
+
+// close returns the session to its pool, if it was acquired from one.
+func (s *session) close() {
+	if s.release != nil {
+		s.release()
+	}
+}
+
+type callerTxKey struct{}
+
+// callerTx returns the caller-supplied transaction a migration run executes
+// in, if any.
+func callerTx(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(callerTxKey{}).(pgx.Tx)
+	return tx, ok
+}
@@ -0,0 +1,80 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+type unsupportedDB struct{ conduit.DB }
+
+func TestMigrator_Migrate_DB(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		"20230602120000_create_b.up.sql": "---- enable-tx ----\nCREATE TABLE b (id INT);",
+	}
+
+	t.Run("should apply migrations, when given a pool", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, pool, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, testutil.CollectSeq2(t, seq), 2)
+		assert.Len(t, appliedMigrations(t, pool), 2)
+		assert.Zero(t, pool.Stat().AcquiredConns(), "session should be released")
+	})
+
+	t.Run("should apply migrations inside caller transaction, when given a tx", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool := poolFactory.Pool(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)), conduit.WithSkipSchemaDriftCheck())
+
+		tx, err := pool.Begin(t.Context())
+		require.NoError(t, err)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, tx, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		report, err := m.Status(t.Context(), tx)
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Rollback(t.Context()))
+
+		// Assert
+		assert.Len(t, results, 2)
+		assert.Len(t, report.Applied(), 2)
+		assert.False(t, testutil.TableExists(t, pool, "a"), "rollback should discard migrations")
+		assert.False(t, testutil.TableExists(t, pool, "b"), "rollback should discard migrations")
+	})
+
+	t.Run("should return ErrUnsupportedDB, when handle exposes no session", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, files)))
+
+		// Act
+		_, err := m.Migrate(t.Context(), conduit.DirectionUp, unsupportedDB{}, nil)
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrUnsupportedDB)
+	})
+}
@@ -0,0 +1,62 @@
+package conduit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/dbsqlc"
+)
+
+type defaultSettingsKey struct{}
+
+// withDefaultSettings returns ctx carrying the settings migrations of a run
+// get unless they declare the same parameter.
+func withDefaultSettings(ctx context.Context, settings []Setting) context.Context {
+	return context.WithValue(ctx, defaultSettingsKey{}, settings)
+}
+
+// defaultSettings returns the default settings of the migration run.
+func defaultSettings(ctx context.Context) []Setting {
+	settings, _ := ctx.Value(defaultSettingsKey{}).([]Setting)
+	return settings
+}
+
+// mergeSettings returns defaults, less the parameters overrides sets,
+// followed by overrides.
+func mergeSettings(defaults, overrides []Setting) []Setting {
+	merged := make([]Setting, 0, len(defaults)+len(overrides))
+
+	for _, s := range defaults {
+		if !slices.ContainsFunc(overrides, func(o Setting) bool { return o.Name == s.Name }) {
+			merged = append(merged, s)
+		}
+	}
+
+	return append(merged, overrides...)
+}
+
+// migrationSettings returns the settings migration runs with in direction
+// dir: those it declares, and the defaults of the run for the rest.
+func migrationSettings(ctx context.Context, migration *conduitregistry.Migration, dir Direction) []Setting {
+	return mergeSettings(defaultSettings(ctx), migration.Settings(dir))
+}
+
+// applySettings sets settings on db, as SET LOCAL when local and as SET
+// otherwise.
+func applySettings(ctx context.Context, db dbsqlc.DBTX, settings []Setting, local bool) error {
+	q := dbsqlc.New()
+
+	for _, s := range settings {
+		if err := q.SetConfig(ctx, db, dbsqlc.SetConfigParams{
+			Name:    s.Name,
+			Value:   s.Value,
+			IsLocal: local,
+		}); err != nil {
+			return fmt.Errorf("failed to set %s to %q: %w", s.Name, s.Value, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package conduit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Migrate_Settings(t *testing.T) {
+	t.Parallel()
+
+	const recordSettings = `CREATE TABLE settings AS SELECT
+	current_setting('lock_timeout') AS lock_timeout,
+	current_setting('statement_timeout') AS statement_timeout,
+	current_setting('work_mem') AS work_mem;`
+
+	newRegistry := func(t *testing.T, directives string) *conduitregistry.Registry {
+		t.Helper()
+
+		fs, _, dir := testutil.NewMigrationsDirBuilder(t).
+			WithFile("20230601120000_record_settings.up.sql", directives+recordSettings).
+			Build()
+
+		return conduitregistry.FromFS(fs, dir)
+	}
+
+	readSettings := func(t *testing.T, conn *pgx.Conn) []string {
+		t.Helper()
+
+		var lockTimeout, statementTimeout, workMem string
+		require.NoError(t, conn.QueryRow(
+			t.Context(),
+			"SELECT lock_timeout, statement_timeout, work_mem FROM settings",
+		).Scan(&lockTimeout, &statementTimeout, &workMem))
+
+		return []string{lockTimeout, statementTimeout, workMem}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		directives string
+	}{
+		{name: "outside a transaction", directives: ""},
+		{name: "inside a transaction", directives: "---- enable-tx ----\n"},
+	} {
+		t.Run("should run migration with declared settings over defaults, when "+tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			_, conn := newConn(t)
+			m := conduit.NewMigrator(
+				conduit.WithRegistry(newRegistry(t, tc.directives+
+					"---- lock-timeout: 3s ----\n---- set: work_mem=256MB ----\n")),
+				conduit.WithDefaultSettings(
+					conduitregistry.LockTimeout(time.Second),
+					conduitregistry.StatementTimeout(time.Minute),
+				),
+			)
+
+			// Act
+			seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+			require.NoError(t, err)
+			testutil.CollectSeq2(t, seq)
+
+			// Assert
+			assert.Equal(t, []string{"3s", "1min", "256MB"}, readSettings(t, conn))
+
+			var lockTimeout string
+			require.NoError(t, conn.QueryRow(t.Context(), "SHOW lock_timeout").Scan(&lockTimeout))
+			assert.Equal(t, "0", lockTimeout, "settings must not outlive the migration")
+		})
+	}
+
+	t.Run("should override default settings, when set in migrate options", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(newRegistry(t, "")),
+			conduit.WithDefaultSettings(conduitregistry.StatementTimeout(time.Minute)),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, &conduit.MigrateOptions{
+			Settings: []conduit.Setting{conduitregistry.StatementTimeout(2 * time.Minute)},
+		})
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		assert.Equal(t, "2min", readSettings(t, conn)[1])
+	})
+}
@@ -0,0 +1,310 @@
+package conduit
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.inout.gg/foundations/must"
+
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/internaldebug"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/pkg/conduitversion"
+)
+
+// MigrationState describes where a migration stands relative to the
+// database.
+type MigrationState string
+
+const (
+	// MigrationStateApplied marks a registry migration recorded in the
+	// history table.
+	MigrationStateApplied MigrationState = "applied"
+
+	// MigrationStatePending marks a registry migration not yet applied, or
+	// a repeatable migration whose content changed since it was applied.
+	MigrationStatePending MigrationState = "pending"
+
+	// MigrationStateMissing marks a migration recorded in the history table
+	// that is not present in the registry.
+	MigrationStateMissing MigrationState = "missing"
+
+	// MigrationStateSkipped marks a migration that would be pending but is
+	// not selected by the tag filter, see [TagFilter].
+	MigrationStateSkipped MigrationState = "skipped"
+
+	// MigrationStateDirty marks a migration that failed part-way outside a
+	// transaction, see [DirtyMigrationError].
+	MigrationStateDirty MigrationState = "dirty"
+)
+
+// MigrationStatus is the state of a single migration.
+//
+// Migration is nil for [MigrationStateMissing]. AppliedAt, Hash and Checksum
+// are only set for migrations recorded in the history table; Hash is the
+// schema hash recorded after the migration was applied and Checksum the
+// content checksum of the migration as it was applied, see
+// [conduitregistry.Migration.Checksum]. UseTx, Hazards and Tags describe
+// the up direction and are only set when the migration is in the registry.
+//
+// DirtyDirection and LastStatement are only set for [MigrationStateDirty]:
+// they are the direction the migration failed in and the number of its
+// statements that succeeded. Migration is nil for a dirty migration missing
+// from the registry.
+type MigrationStatus struct {
+	AppliedAt      time.Time
+	Migration      *Migration
+	State          MigrationState
+	Version        conduitversion.Version
+	Namespace      string
+	Name           string
+	Hash           string
+	Checksum       string
+	DirtyDirection Direction
+	Hazards        []conduitregistry.Hazard
+	Tags           []string
+	LastStatement  int
+	UseTx          bool
+}
+
+// Key returns the migration key in the same format as [Migration.Key].
+func (s *MigrationStatus) Key() string {
+	return conduitregistry.Key(s.Namespace, s.Version.String(), s.Name)
+}
+
+// StatusReport lists every migration known to either the registry or the
+// history table, ordered the same way migrations are applied, see
+// [conduitregistry.Registry.Sorted]. Migrations missing from the registry
+// are placed among them by version, namespace and name.
+type StatusReport struct {
+	Migrations []*MigrationStatus
+}
+
+// Applied returns the migrations in [MigrationStateApplied].
+func (r *StatusReport) Applied() []*MigrationStatus { return r.filter(MigrationStateApplied) }
+
+// Pending returns the migrations in [MigrationStatePending].
+func (r *StatusReport) Pending() []*MigrationStatus { return r.filter(MigrationStatePending) }
+
+// Missing returns the migrations in [MigrationStateMissing].
+func (r *StatusReport) Missing() []*MigrationStatus { return r.filter(MigrationStateMissing) }
+
+// Skipped returns the migrations in [MigrationStateSkipped].
+func (r *StatusReport) Skipped() []*MigrationStatus { return r.filter(MigrationStateSkipped) }
+
+// Dirty returns the migrations in [MigrationStateDirty].
+func (r *StatusReport) Dirty() []*MigrationStatus { return r.filter(MigrationStateDirty) }
+
+func (r *StatusReport) filter(state MigrationState) []*MigrationStatus {
+	return sliceutil.Filter(r.Migrations, func(s *MigrationStatus) bool { return s.State == state })
+}
+
+// Modified reports whether an applied migration's content no longer matches
+// the checksum recorded when it was applied. Migrations recorded without a
+// checksum, such as Go migrations, are never reported as modified.
+func (s *MigrationStatus) Modified() bool {
+	return s.State == MigrationStateApplied &&
+		s.Checksum != "" &&
+		s.Checksum != s.Migration.Checksum(DirectionUp)
+}
+
+// Outdated reports whether s is a repeatable migration that was applied
+// but whose content changed since, and so is to be reapplied unless the tag
+// filter skips it.
+func (s *MigrationStatus) Outdated() bool {
+	return s.isPending() && s.Version.IsRepeatable() && !s.AppliedAt.IsZero()
+}
+
+// isPending reports whether s is yet to be applied, whether or not the tag
+// filter selects it.
+func (s *MigrationStatus) isPending() bool {
+	return s.State == MigrationStatePending || s.State == MigrationStateSkipped
+}
+
+// Status reports which registry migrations are applied, pending or skipped
+// by the tag filter set with [WithTags], and which recorded migrations are
+// missing from the registry or dirty.
+//
+// Status only reads the history table: it neither takes the advisory lock
+// nor runs the schema drift check. A table created by an earlier version of
+// conduit is read as if upgraded, but is left unchanged.
+func (m *Migrator) Status(ctx context.Context, db DB) (*StatusReport, error) {
+	return m.status(ctx, db, m.tags)
+}
+
+// status is [Migrator.Status] with migrations not selected by tags skipped.
+func (m *Migrator) status(ctx context.Context, db DB, tags TagFilter) (*StatusReport, error) {
+	s, err := acquireSession(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	sorted, err := m.registry.Sorted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to order migrations: %w", err)
+	}
+
+	rows, err := m.migrationRecords(ctx, s.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := m.registry.Migrations()
+	statuses := make([]*MigrationStatus, 0, max(len(registered), len(rows)))
+
+	for _, row := range rows {
+		key := conduitregistry.Key(row.Namespace, row.Version, row.Name)
+
+		status := &MigrationStatus{
+			AppliedAt: row.CreatedAt.Time,
+			State:     MigrationStateMissing,
+			Namespace: row.Namespace,
+			Name:      row.Name,
+			Hash:      row.Hash,
+			Checksum:  row.Checksum,
+		}
+
+		if migration, ok := registered[key]; ok {
+			delete(registered, key)
+
+			status.State = MigrationStateApplied
+			status.setMigration(migration)
+
+			if migration.Version().IsRepeatable() && row.Checksum != migration.Checksum(DirectionUp) {
+				status.State = pendingState(migration, tags)
+			}
+		} else {
+			version, err := conduitversion.Parse(row.Version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse recorded migration %s: %w", key, err)
+			}
+
+			status.Version = version
+		}
+
+		if row.DirtyDirection != "" {
+			status.State = MigrationStateDirty
+			status.DirtyDirection = Direction(row.DirtyDirection)
+			status.LastStatement = int(row.LastStatement)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, migration := range registered {
+		status := &MigrationStatus{
+			State:     pendingState(migration, tags),
+			Namespace: migration.Namespace(),
+			Name:      migration.Name(),
+		}
+		status.setMigration(migration)
+
+		statuses = append(statuses, status)
+	}
+
+	report := &StatusReport{Migrations: orderStatuses(sorted, statuses)}
+	m.observer.ObservePending(len(report.Pending()))
+
+	return report, nil
+}
+
+// pendingState returns the state of migration when it is yet to be applied.
+func pendingState(migration *Migration, tags TagFilter) MigrationState {
+	if !tags.Match(migration.Tags()) {
+		return MigrationStateSkipped
+	}
+
+	return MigrationStatePending
+}
+
+func (s *MigrationStatus) dirtyError() error {
+	return &DirtyMigrationError{
+		Migration:     s.Key(),
+		Direction:     s.DirtyDirection,
+		LastStatement: s.LastStatement,
+	}
+}
+
+func (s *MigrationStatus) setMigration(migration *Migration) {
+	s.Migration = migration
+	s.Version = migration.Version()
+	s.UseTx = must.Must(migration.UseTx(DirectionUp))
+	s.Hazards = migration.Hazards(DirectionUp)
+	s.Tags = migration.Tags()
+}
+
+func (m *Migrator) migrationRecords(
+	ctx context.Context,
+	conn *pgx.Conn,
+) ([]dbsqlc.AllMigrationRecordsRow, error) {
+	var rows []dbsqlc.AllMigrationRecordsRow
+
+	ok, err := m.readHistory(ctx, conn, func() (err error) {
+		rows, err = dbsqlc.New().AllMigrationRecords(ctx, dbsqlc.WithTable(conn, m.table))
+		if err != nil {
+			return fmt.Errorf("failed to fetch existing migrations: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		internaldebug.Log("%s table is not found", m.table)
+	}
+
+	return rows, nil
+}
+
+// orderStatuses orders statuses the way migrations are applied: the
+// statuses of registry migrations as in sorted, and the statuses of
+// migrations missing from the registry before the first of them that
+// compares greater.
+func orderStatuses(sorted []*Migration, statuses []*MigrationStatus) []*MigrationStatus {
+	registered := make(map[string]*MigrationStatus, len(sorted))
+	missing := make([]*MigrationStatus, 0, len(statuses)-len(sorted))
+
+	for _, s := range statuses {
+		if s.Migration == nil {
+			missing = append(missing, s)
+		} else {
+			registered[s.Key()] = s
+		}
+	}
+
+	slices.SortFunc(missing, compareStatuses)
+
+	ordered := make([]*MigrationStatus, 0, len(statuses))
+
+	for _, migration := range sorted {
+		s := registered[migration.Key()]
+		for len(missing) > 0 && compareStatuses(missing[0], s) < 0 {
+			ordered = append(ordered, missing[0])
+			missing = missing[1:]
+		}
+
+		ordered = append(ordered, s)
+	}
+
+	return append(ordered, missing...)
+}
+
+func compareStatuses(a, b *MigrationStatus) int {
+	if c := a.Version.Compare(b.Version); c != 0 {
+		return c
+	}
+
+	if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(a.Name, b.Name)
+}
@@ -0,0 +1,99 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestMigrator_Status(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should order migrations as applied, when a migration depends on a newer one", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": "---- depends-on: 20230602120000_create_b ----\nCREATE TABLE a (id INT);",
+			"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+		})))
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, report.Migrations, 2)
+		assert.Equal(t, "20230602120000_create_b", report.Migrations[0].Key())
+		assert.Equal(t, "20230601120000_create_a", report.Migrations[1].Key())
+	})
+
+	t.Run("should report applied, pending and missing migrations", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		applied := testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+			"20230602120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+		})
+
+		seq, err := conduit.NewMigrator(
+			conduit.WithRegistry(applied),
+			conduit.WithSkipSchemaDriftCheck(),
+		).Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+			"20230603120000_create_c.up.sql": "---- enable-tx ----\nCREATE TABLE c (id INT);",
+		})))
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, report.Migrations, 3)
+
+		assert.Equal(t, "20230601120000_create_a", report.Migrations[0].Key())
+		assert.Equal(t, conduit.MigrationStateApplied, report.Migrations[0].State)
+		assert.False(t, report.Migrations[0].AppliedAt.IsZero())
+		assert.NotEmpty(t, report.Migrations[0].Hash)
+
+		assert.Equal(t, "20230602120000_create_b", report.Migrations[1].Key())
+		assert.Equal(t, conduit.MigrationStateMissing, report.Migrations[1].State)
+		assert.Nil(t, report.Migrations[1].Migration)
+
+		assert.Equal(t, "20230603120000_create_c", report.Migrations[2].Key())
+		assert.Equal(t, conduit.MigrationStatePending, report.Migrations[2].State)
+		assert.True(t, report.Migrations[2].AppliedAt.IsZero())
+		assert.True(t, report.Migrations[2].UseTx)
+		assert.Len(t, report.Pending(), 1)
+	})
+
+	t.Run("should report every migration as pending, when history table does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		})))
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, report.Pending(), 1)
+		assert.Empty(t, report.Applied())
+	})
+}
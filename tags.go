@@ -0,0 +1,66 @@
+package conduit
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"go.inout.gg/conduit/internal/sliceutil"
+)
+
+// TagFilter selects the tagged migrations to apply, see
+// [conduitregistry.TagsDirectivePrefix].
+//
+// Untagged migrations are always selected. A tagged migration is selected
+// only when one of its tags is in Include and none is in Exclude, so that
+// a migration tagged dev never runs unless dev is included.
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Match reports whether f selects a migration with tags.
+func (f TagFilter) Match(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	hasAny := func(filter []string) bool {
+		return slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(filter, tag) })
+	}
+
+	return hasAny(f.Include) && !hasAny(f.Exclude)
+}
+
+// isZero reports whether f sets no tags.
+func (f TagFilter) isZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// tagFilter returns the tag filter of a Migrate call: the one of opts when
+// set, and the one set with [WithTags] otherwise.
+func (m *Migrator) tagFilter(opts *MigrateOptions) TagFilter {
+	if !opts.Tags.isZero() {
+		return opts.Tags
+	}
+
+	return m.tags
+}
+
+// selectTagged returns the migrations tags selects, logging the others.
+func (m *Migrator) selectTagged(ctx context.Context, migrations []*Migration, tags TagFilter) []*Migration {
+	return sliceutil.Filter(migrations, func(migration *Migration) bool {
+		if tags.Match(migration.Tags()) {
+			return true
+		}
+
+		m.logger.InfoContext(
+			ctx,
+			"skipping migration not selected by tags",
+			slog.String("migration", migration.Key()),
+			slog.Any("tags", migration.Tags()),
+		)
+
+		return false
+	})
+}
@@ -0,0 +1,133 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func TestTagFilter_Match(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		filter conduit.TagFilter
+		tags   []string
+		want   bool
+	}{
+		{name: "select untagged migration, when no tags are selected", want: true},
+		{
+			name:   "select untagged migration, when tags are excluded",
+			filter: conduit.TagFilter{Exclude: []string{"dev"}},
+			want:   true,
+		},
+		{name: "skip tagged migration, when no tags are selected", tags: []string{"dev"}, want: false},
+		{
+			name:   "select tagged migration, when one of its tags is included",
+			filter: conduit.TagFilter{Include: []string{"seed"}},
+			tags:   []string{"dev", "seed"},
+			want:   true,
+		},
+		{
+			name:   "skip tagged migration, when one of its tags is excluded",
+			filter: conduit.TagFilter{Include: []string{"seed"}, Exclude: []string{"dev"}},
+			tags:   []string{"dev", "seed"},
+			want:   false,
+		},
+	} {
+		t.Run("should "+tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			got := tc.filter.Match(tc.tags)
+
+			// Assert
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMigrator_Migrate_Tags(t *testing.T) {
+	t.Parallel()
+
+	newRegistry := func(t *testing.T) *conduitregistry.Registry {
+		t.Helper()
+
+		return testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			"20230602120000_seed_users.up.sql":   "---- tags: dev,seed ----\nINSERT INTO users VALUES (1);",
+			"20230603120000_create_posts.up.sql": "CREATE TABLE posts (id INT);",
+		})
+	}
+
+	t.Run("should skip tagged migrations and report them, when their tags are not selected", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		assert.Equal(t, []dbsqlc.TestAllMigrationsRow{
+			{Version: "20230601120000", Name: "create_users"},
+			{Version: "20230603120000", Name: "create_posts"},
+		}, appliedMigrations(t, pool))
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Empty(t, report.Pending())
+		require.Len(t, report.Skipped(), 1)
+		assert.Equal(t, "20230602120000_seed_users", report.Skipped()[0].Key())
+		assert.Equal(t, []string{"dev", "seed"}, report.Skipped()[0].Tags)
+	})
+
+	t.Run("should apply tagged migrations, when their tags are included", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(conduit.WithRegistry(newRegistry(t)))
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, &conduit.MigrateOptions{
+			Tags: conduit.TagFilter{Include: []string{"dev"}},
+		})
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		assert.Len(t, results, 3)
+		assert.Len(t, appliedMigrations(t, pool), 3)
+	})
+
+	t.Run("should skip tagged migrations, when one of their tags is excluded", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(newRegistry(t)),
+			conduit.WithTags(conduit.TagFilter{Include: []string{"dev"}, Exclude: []string{"seed"}}),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		assert.Len(t, appliedMigrations(t, pool), 2)
+	})
+}
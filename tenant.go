@@ -0,0 +1,354 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"go.inout.gg/conduit/internal/sliceutil"
+)
+
+// DefaultTenantConcurrency is the number of tenants a [TenantRunner]
+// migrates at once, unless set with [WithTenantConcurrency].
+const DefaultTenantConcurrency = 4
+
+// TenantFailurePolicy controls how a [TenantRunner] reacts to a tenant
+// failing to migrate.
+type TenantFailurePolicy string
+
+const (
+	TenantFailureStop     TenantFailurePolicy = "stop"     // migrate no further tenants
+	TenantFailureContinue TenantFailurePolicy = "continue" // migrate the other tenants
+)
+
+var (
+	ErrUnknownTenantFailurePolicy = errors.New("unknown tenant failure policy: expected stop or continue")
+	ErrInvalidTenant              = errors.New("invalid tenant schema")
+)
+
+// ParseTenantFailurePolicy parses s as a [TenantFailurePolicy].
+func ParseTenantFailurePolicy(s string) (TenantFailurePolicy, error) {
+	switch p := TenantFailurePolicy(s); p {
+	case TenantFailureStop, TenantFailureContinue:
+		return p, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownTenantFailurePolicy, s)
+}
+
+type tenantConfig struct {
+	FailurePolicy TenantFailurePolicy
+	SharedSchemas []string
+	Concurrency   int
+}
+
+// TenantOption configures how a [TenantRunner] goes through the tenants,
+// unlike an [Option], which configures the Migrator of every tenant.
+type TenantOption func(*tenantConfig)
+
+// WithTenantConcurrency sets how many tenants a [TenantRunner] migrates at
+// once. Defaults to [DefaultTenantConcurrency].
+func WithTenantConcurrency(n int) TenantOption {
+	return func(c *tenantConfig) { c.Concurrency = n }
+}
+
+// WithTenantFailurePolicy sets how a [TenantRunner] reacts to a tenant
+// failing to migrate. Defaults to [TenantFailureStop].
+func WithTenantFailurePolicy(p TenantFailurePolicy) TenantOption {
+	return func(c *tenantConfig) { c.FailurePolicy = p }
+}
+
+// WithSharedSchemas sets the schemas a [TenantRunner] puts on the
+// search_path after the tenant schema, e.g. the schema extensions are
+// installed in.
+func WithSharedSchemas(schemas ...string) TenantOption {
+	return func(c *tenantConfig) { c.SharedSchemas = schemas }
+}
+
+// TenantResult is a [MigrationResult] of the tenant with the given schema.
+type TenantResult struct {
+	Result *MigrationResult
+	Schema string
+}
+
+// TenantError is returned when the tenant with the given schema fails to
+// migrate.
+type TenantError struct {
+	Err    error
+	Schema string
+}
+
+func (e *TenantError) Error() string { return fmt.Sprintf("tenant %s: %v", e.Schema, e.Err) }
+
+func (e *TenantError) Unwrap() error { return e.Err }
+
+// TenantStatus is the [StatusReport] of the tenant with the given schema.
+type TenantStatus struct {
+	Report *StatusReport
+	Schema string
+}
+
+// TenantRunner migrates a database with one schema per tenant, each of which
+// gets every migration of the registry.
+//
+// Every tenant is migrated as by its own [Migrator]: the history table
+// lives in the tenant schema, see [WithHistorySchema], the advisory lock is
+// that of the tenant's history table, or the key set with [WithLockKey]
+// followed by "/<schema>", and migrations run with the search_path set to
+// the tenant schema followed by the schemas set with [WithSharedSchemas]. A
+// migration that sets search_path with a directive replaces it.
+//
+// No schema hash is recorded and neither the schema drift check nor the
+// schema verification is run, as the schema hash covers every tenant rather
+// than the tenant schema. Hooks and the Observer are called from several
+// tenants at once.
+type TenantRunner struct {
+	cfg       config
+	tenantCfg tenantConfig
+}
+
+// NewTenantRunner creates a TenantRunner whose tenants are each migrated by
+// a Migrator configured with opts, going through the tenants as configured
+// with tenantOpts.
+func NewTenantRunner(opts []Option, tenantOpts ...TenantOption) *TenantRunner {
+	//nolint:exhaustruct
+	cfg := config{}
+	for _, c := range opts {
+		c(&cfg)
+	}
+
+	//nolint:exhaustruct
+	tenantCfg := tenantConfig{}
+	for _, c := range tenantOpts {
+		c(&tenantCfg)
+	}
+
+	if tenantCfg.Concurrency <= 0 {
+		tenantCfg.Concurrency = DefaultTenantConcurrency
+	}
+
+	if tenantCfg.FailurePolicy == "" {
+		tenantCfg.FailurePolicy = TenantFailureStop
+	}
+
+	return &TenantRunner{cfg: cfg, tenantCfg: tenantCfg}
+}
+
+// QueryTenants runs query, which must return a single text column, and
+// returns the tenant schemas it lists, e.g.
+// SELECT schema_name FROM tenants WHERE active.
+func QueryTenants(ctx context.Context, db DB, query string) ([]string, error) {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+
+	tenants, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// tenantEvent is a result or an error of a tenant, passed from the worker
+// migrating it to the iterator.
+type tenantEvent struct {
+	result *TenantResult
+	err    error
+}
+
+// Migrate migrates the tenants with the given schemas in the given
+// direction, as [Migrator.Migrate] does with opts, running up to the
+// configured number of tenants at once on connections of pool.
+//
+// The returned iterator yields the results of all tenants as each
+// migration completes, and a [*TenantError] for each tenant that fails.
+// Under [TenantFailureStop], a failure stops the other tenants after their
+// current migration and no further tenant is started; under
+// [TenantFailureContinue], the other tenants are migrated and the iteration
+// goes on. Stopping the iteration early stops the tenants the same way.
+// Tenant schemas must exist; [ErrInvalidTenant] is returned for an empty or
+// duplicate schema.
+func (r *TenantRunner) Migrate(
+	ctx context.Context,
+	dir Direction,
+	pool *pgxpool.Pool,
+	tenants []string,
+	opts *MigrateOptions,
+) (iter.Seq2[*TenantResult, error], error) {
+	if err := checkTenants(tenants); err != nil {
+		return nil, err
+	}
+
+	if opts == nil {
+		opts = new(MigrateOptions)
+	}
+
+	return func(yield func(*TenantResult, error) bool) {
+		var (
+			stopped atomic.Bool
+			wg      sync.WaitGroup
+		)
+
+		queue := make(chan string)
+		events := make(chan tenantEvent)
+
+		for range min(r.tenantCfg.Concurrency, len(tenants)) {
+			wg.Go(func() {
+				for schema := range queue {
+					if !stopped.Load() {
+						r.migrateTenant(ctx, dir, pool, schema, *opts, &stopped, events)
+					}
+				}
+			})
+		}
+
+		go func() {
+			defer close(queue)
+
+			for _, schema := range tenants {
+				queue <- schema
+			}
+		}()
+
+		go func() {
+			wg.Wait()
+			close(events)
+		}()
+
+		// Drain the events after the iteration is stopped, so that the
+		// tenants still running finish their current migration.
+		done := false
+		for e := range events {
+			if !done && !yield(e.result, e.err) {
+				done = true
+
+				stopped.Store(true)
+			}
+		}
+	}, nil
+}
+
+// migrateTenant migrates the tenant with the given schema, sending its
+// results to events, until it is done or stopped is set.
+func (r *TenantRunner) migrateTenant(
+	ctx context.Context,
+	dir Direction,
+	pool *pgxpool.Pool,
+	schema string,
+	opts MigrateOptions,
+	stopped *atomic.Bool,
+	events chan<- tenantEvent,
+) {
+	fail := func(err error) {
+		if r.tenantCfg.FailurePolicy == TenantFailureStop {
+			stopped.Store(true)
+		}
+
+		events <- tenantEvent{result: nil, err: &TenantError{Schema: schema, Err: err}}
+	}
+
+	seq, err := r.migrator(schema).Migrate(ctx, dir, pool, &opts)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	for result, err := range seq {
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		events <- tenantEvent{result: &TenantResult{Schema: schema, Result: result}, err: nil}
+
+		if stopped.Load() {
+			return
+		}
+	}
+}
+
+// Status reports the status of every tenant with the given schemas, see
+// [Migrator.Status].
+func (r *TenantRunner) Status(ctx context.Context, db DB, tenants []string) ([]*TenantStatus, error) {
+	if err := checkTenants(tenants); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*TenantStatus, 0, len(tenants))
+
+	for _, schema := range tenants {
+		report, err := r.migrator(schema).Status(ctx, db)
+		if err != nil {
+			return nil, &TenantError{Schema: schema, Err: err}
+		}
+
+		statuses = append(statuses, &TenantStatus{Schema: schema, Report: report})
+	}
+
+	return statuses, nil
+}
+
+// migrator returns the Migrator of the tenant with the given schema.
+func (r *TenantRunner) migrator(schema string) *Migrator {
+	cfg := r.cfg
+	cfg.HistorySchema = schema
+	cfg.Settings = mergeSettings(r.cfg.Settings, []Setting{r.searchPath(schema)})
+	cfg.SkipSchemaDriftCheck = true
+	cfg.SkipSchemaHash = true
+	cfg.VerifySchema = false
+
+	if cfg.LockKey != "" {
+		cfg.LockKey += "/" + schema
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	cfg.Logger = cfg.Logger.With(slog.String("tenant", schema))
+
+	return newMigrator(&cfg)
+}
+
+// searchPath returns the search_path the migrations of the tenant with the
+// given schema run with.
+func (r *TenantRunner) searchPath(schema string) Setting {
+	schemas := append([]string{schema}, r.tenantCfg.SharedSchemas...)
+
+	return Setting{
+		Name: "search_path",
+		Value: strings.Join(sliceutil.Map(schemas, func(s string) string {
+			return pgx.Identifier{s}.Sanitize()
+		}), ", "),
+	}
+}
+
+// checkTenants returns [ErrInvalidTenant] if a tenant schema is empty or
+// listed twice.
+func checkTenants(tenants []string) error {
+	seen := make(map[string]struct{}, len(tenants))
+
+	for _, schema := range tenants {
+		if schema == "" {
+			return fmt.Errorf("%w: empty schema name", ErrInvalidTenant)
+		}
+
+		if _, ok := seen[schema]; ok {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidTenant, schema)
+		}
+
+		seen[schema] = struct{}{}
+	}
+
+	return nil
+}
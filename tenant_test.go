@@ -0,0 +1,262 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/migrations"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+// newTenants creates the schemas of tenants, each with its history table.
+func newTenants(t *testing.T, pool *pgxpool.Pool, tenants ...string) {
+	t.Helper()
+
+	for _, schema := range tenants {
+		testutil.Exec(t, pool, string(migrations.SchemaFor(migrations.NewTable(schema, ""))))
+	}
+}
+
+func tenantMigrations(t *testing.T, pool *pgxpool.Pool, schema string) []dbsqlc.TestAllMigrationsRow {
+	t.Helper()
+
+	rows, err := dbsqlc.New().TestAllMigrations(
+		t.Context(),
+		dbsqlc.WithTable(pool, migrations.NewTable(schema, "")),
+	)
+	require.NoError(t, err)
+
+	return rows
+}
+
+func tenantTableExists(t *testing.T, pool *pgxpool.Pool, schema, name string) bool {
+	t.Helper()
+
+	var exists bool
+
+	err := pool.QueryRow(
+		t.Context(),
+		"SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = $1 AND table_name = $2)",
+		schema,
+		name,
+	).Scan(&exists)
+	require.NoError(t, err)
+
+	return exists
+}
+
+func TestTenantRunner_Migrate(t *testing.T) {
+	t.Parallel()
+
+	newRegistry := func(t *testing.T) *conduitregistry.Registry {
+		t.Helper()
+
+		return testregistry.NewRegistry(t, map[string]string{
+			"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			"20230602120000_seed_users.up.sql":   "---- enable-tx ----\nINSERT INTO users SELECT id FROM seed;",
+		})
+	}
+
+	t.Run("should migrate every tenant in its own schema, when tenants are listed", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+		newTenants(t, pool, "tenant_a", "tenant_b")
+		testutil.Exec(t, pool, "CREATE TABLE tenant_a.seed (id INT); CREATE TABLE tenant_b.seed (id INT);")
+
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(newRegistry(t))})
+
+		// Act
+		seq, err := r.Migrate(t.Context(), conduit.DirectionUp, pool, []string{"tenant_a", "tenant_b"}, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 4)
+
+		for _, schema := range []string{"tenant_a", "tenant_b"} {
+			assert.True(t, tenantTableExists(t, pool, schema, "users"), schema)
+			assert.Len(t, tenantMigrations(t, pool, schema), 2, schema)
+		}
+
+		assert.Empty(t, appliedMigrations(t, pool))
+	})
+
+	t.Run("should record no schema hash, when tenant is migrated", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+		newTenants(t, pool, "tenant_a")
+		testutil.Exec(t, pool, "CREATE TABLE tenant_a.seed (id INT);")
+
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(newRegistry(t))})
+
+		// Act
+		seq, err := r.Migrate(t.Context(), conduit.DirectionUp, pool, []string{"tenant_a"}, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		rows, err := pool.Query(t.Context(), "SELECT hash FROM tenant_a.conduit_migrations")
+		require.NoError(t, err)
+		hashes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", ""}, hashes)
+	})
+
+	t.Run("should stop other tenants, when a tenant fails under stop policy", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+		newTenants(t, pool, "tenant_a", "tenant_b")
+		testutil.Exec(t, pool, "CREATE TABLE tenant_b.seed (id INT);")
+
+		r := conduit.NewTenantRunner(
+			[]conduit.Option{conduit.WithRegistry(newRegistry(t))},
+			conduit.WithTenantConcurrency(1),
+		)
+
+		// Act
+		seq, err := r.Migrate(t.Context(), conduit.DirectionUp, pool, []string{"tenant_a", "tenant_b"}, nil)
+		require.NoError(t, err)
+
+		var errs []error
+		for _, err := range seq {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		// Assert
+		require.Len(t, errs, 1)
+
+		var tenantErr *conduit.TenantError
+		require.ErrorAs(t, errs[0], &tenantErr)
+		assert.Equal(t, "tenant_a", tenantErr.Schema)
+		assert.Empty(t, tenantMigrations(t, pool, "tenant_b"))
+	})
+
+	t.Run("should migrate other tenants, when a tenant fails under continue policy", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+		newTenants(t, pool, "tenant_a", "tenant_b")
+		testutil.Exec(t, pool, "CREATE TABLE tenant_b.seed (id INT);")
+
+		r := conduit.NewTenantRunner(
+			[]conduit.Option{conduit.WithRegistry(newRegistry(t))},
+			conduit.WithTenantConcurrency(1),
+			conduit.WithTenantFailurePolicy(conduit.TenantFailureContinue),
+		)
+
+		// Act
+		seq, err := r.Migrate(t.Context(), conduit.DirectionUp, pool, []string{"tenant_a", "tenant_b"}, nil)
+		require.NoError(t, err)
+
+		var errs []error
+		for _, err := range seq {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		// Assert
+		require.Len(t, errs, 1)
+		assert.Len(t, tenantMigrations(t, pool, "tenant_a"), 1)
+		assert.Len(t, tenantMigrations(t, pool, "tenant_b"), 2)
+	})
+
+	t.Run("should return error, when a tenant is listed twice", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		r := conduit.NewTenantRunner([]conduit.Option{conduit.WithRegistry(newRegistry(t))})
+
+		// Act
+		_, err := r.Migrate(t.Context(), conduit.DirectionUp, nil, []string{"tenant_a", "tenant_a"}, nil)
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrInvalidTenant)
+	})
+}
+
+func TestTenantRunner_Status(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report each tenant, when tenants are at different versions", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+		newTenants(t, pool, "tenant_a", "tenant_b")
+
+		r := conduit.NewTenantRunner([]conduit.Option{
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+			})),
+		})
+
+		seq, err := r.Migrate(t.Context(), conduit.DirectionUp, pool, []string{"tenant_a"}, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Act
+		statuses, err := r.Status(t.Context(), pool, []string{"tenant_a", "tenant_b"})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		assert.Equal(t, "tenant_a", statuses[0].Schema)
+		assert.Len(t, statuses[0].Report.Applied(), 1)
+		assert.Equal(t, "tenant_b", statuses[1].Schema)
+		assert.Len(t, statuses[1].Report.Pending(), 1)
+	})
+}
+
+func TestQueryTenants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return schemas, when query lists them", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, _ := newConn(t)
+
+		// Act
+		tenants, err := conduit.QueryTenants(
+			t.Context(),
+			pool,
+			"SELECT unnest(ARRAY['tenant_a', 'tenant_b'])",
+		)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tenant_a", "tenant_b"}, tenants)
+	})
+}
+
+func TestParseTenantFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return error, when policy is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		// Act
+		_, err := conduit.ParseTenantFailurePolicy("retry")
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrUnknownTenantFailurePolicy)
+	})
+}
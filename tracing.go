@@ -0,0 +1,100 @@
+package conduit
+
+import (
+	"context"
+	"iter"
+
+	"github.com/jackc/pgx/v5"
+	"go.inout.gg/foundations/must"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.inout.gg/conduit/conduitregistry"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/tracing"
+)
+
+// Span attributes set by conduit.
+const (
+	attrVersion    = attribute.Key("conduit.migration.version")
+	attrName       = attribute.Key("conduit.migration.name")
+	attrNamespace  = attribute.Key("conduit.migration.namespace")
+	attrDirection  = attribute.Key("conduit.direction")
+	attrTx         = attribute.Key("conduit.migration.tx")
+	attrHazards    = attribute.Key("conduit.migration.hazards")
+	attrMigrations = attribute.Key("conduit.migrations")
+	attrSchemaHash = attribute.Key("conduit.schema_hash")
+	attrAttempts   = attribute.Key("conduit.migration.attempts")
+	attrBackoff    = attribute.Key("conduit.migration.backoff")
+)
+
+// NewQueryTracer returns a pgx.QueryTracer that creates a span for each
+// query run on a connection configured with it. Set it as the Tracer of the
+// pgx.ConnConfig behind the [DB] passed to a Migrator, so that every
+// migration statement gets a span under the span of its migration.
+//
+// When tp is nil, the global tracer provider is used.
+func NewQueryTracer(tp trace.TracerProvider) pgx.QueryTracer {
+	return &queryTracer{tracer: tracing.Tracer(tp)}
+}
+
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func (t *queryTracer) TraceQueryStart(
+	ctx context.Context,
+	_ *pgx.Conn,
+	data pgx.TraceQueryStartData,
+) context.Context {
+	ctx, _ = t.tracer.Start(
+		ctx,
+		"conduit.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(data.SQL)),
+	)
+
+	return ctx
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	tracing.End(trace.SpanFromContext(ctx), data.Err)
+}
+
+// migrationAttributes describes migration applied in direction dir.
+func migrationAttributes(migration *Migration, dir Direction) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attrVersion.String(migration.Version().String()),
+		attrName.String(migration.Name()),
+		attrNamespace.String(migration.Namespace()),
+		attrDirection.String(string(dir)),
+		attrTx.Bool(must.Must(migration.UseTx(dir))),
+		attrHazards.StringSlice(sliceutil.Map(migration.Hazards(dir), func(h conduitregistry.Hazard) string {
+			return h.Type
+		})),
+	}
+}
+
+// traceSeq returns an iterator over seq that ends span, recording the
+// error yielded, if any, once the iteration completes or is stopped early.
+func traceSeq(
+	span trace.Span,
+	seq iter.Seq2[*MigrationResult, error],
+) iter.Seq2[*MigrationResult, error] {
+	return func(yield func(*MigrationResult, error) bool) {
+		var err error
+
+		defer func() { tracing.End(span, err) }()
+
+		for result, resultErr := range seq {
+			if resultErr != nil {
+				err = resultErr
+			}
+
+			if !yield(result, resultErr) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package conduit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/sliceutil"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+func newTracerProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+
+	return tp, exporter
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	return sliceutil.Map(spans, func(s tracetest.SpanStub) string { return s.Name })
+}
+
+func TestNewQueryTracer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should record query span, when query succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		tp, exporter := newTracerProvider(t)
+		tracer := conduit.NewQueryTracer(tp)
+
+		// Act
+		ctx := tracer.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+		// Assert
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "conduit.query", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, attribute.String("db.query.text", "SELECT 1"))
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+
+	t.Run("should record error, when query fails", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		tp, exporter := newTracerProvider(t)
+		tracer := conduit.NewQueryTracer(tp)
+
+		// Act
+		ctx := tracer.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1/0"})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("division by zero")})
+
+		// Assert
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "division by zero", spans[0].Status.Description)
+	})
+}
+
+func TestMigrator_Migrate_Tracing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should create spans for run and migrations, when tracer provider is set", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		tp, exporter := newTracerProvider(t)
+
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+			})),
+			conduit.WithTracerProvider(tp),
+		)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		spans := exporter.GetSpans()
+		assert.ElementsMatch(t, []string{
+			"conduit.drift_check",
+			"conduit.schema_hash",
+			"conduit.migration",
+			"conduit.migrate",
+		}, spanNames(spans))
+
+		for _, span := range spans {
+			if span.Name != "conduit.migration" {
+				continue
+			}
+
+			assert.Contains(t, span.Attributes, attribute.String("conduit.migration.version", "20230601120000"))
+			assert.Contains(t, span.Attributes, attribute.String("conduit.migration.name", "create_a"))
+			assert.Contains(t, span.Attributes, attribute.String("conduit.direction", "up"))
+			assert.Contains(t, span.Attributes, attribute.Bool("conduit.migration.tx", false))
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package conduit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.inout.gg/conduit/internal/dbsqlc"
+	"go.inout.gg/conduit/internal/internaldebug"
+	"go.inout.gg/conduit/internal/migrations"
+)
+
+// historyExists reports whether the history table exists.
+func (m *Migrator) historyExists(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	ok, err := dbsqlc.New().DoesTableExist(ctx, conn, m.table.String())
+	if err != nil {
+		return false, fmt.Errorf("failed to check if migrations table exists: %w", err)
+	}
+
+	return ok, nil
+}
+
+// createHistory creates the history table, and the migration log kept
+// alongside it, when the table is missing.
+func (m *Migrator) createHistory(ctx context.Context, conn *pgx.Conn) error {
+	ok, err := m.historyExists(ctx, conn)
+	if err != nil || ok {
+		return err
+	}
+
+	internaldebug.Log("creating %s table", m.table)
+
+	if _, err := conn.Exec(ctx, string(migrations.SchemaFor(m.table))); err != nil {
+		return fmt.Errorf("failed to create migrations table %s: %w", m.table, err)
+	}
+
+	return nil
+}
+
+// historyOutdated reports whether the history table, which must exist, was
+// created by an earlier version of conduit and lacks something
+// [migrations.UpgradeFor] adds.
+func (m *Migrator) historyOutdated(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	var outdated bool
+
+	if err := conn.QueryRow(ctx, string(migrations.OutdatedFor(m.table))).Scan(&outdated); err != nil {
+		return false, fmt.Errorf("failed to check migrations table %s: %w", m.table, err)
+	}
+
+	return outdated, nil
+}
+
+// upgradeHistory brings a history table created by an earlier version of
+// conduit up to date. It must be called with the advisory lock held.
+//
+// The schema hash recorded with each migration covers the history table, so
+// upgrading the table changes the live schema hash. Rows recorded with the
+// hash of the schema as it was before the upgrade are re-recorded with the
+// hash of the upgraded schema, so that the schema drift check keeps passing;
+// a schema that had drifted before the upgrade keeps failing it.
+func (m *Migrator) upgradeHistory(ctx context.Context, conn *pgx.Conn) error {
+	ok, err := m.historyExists(ctx, conn)
+	if err != nil || !ok {
+		return err
+	}
+
+	outdated, err := m.historyOutdated(ctx, conn)
+	if err != nil || !outdated {
+		return err
+	}
+
+	internaldebug.Log("upgrading %s table", m.table)
+
+	before, err := m.schemaHash(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Exec(ctx, string(migrations.UpgradeFor(m.table))); err != nil {
+		return fmt.Errorf("failed to upgrade migrations table %s: %w", m.table, err)
+	}
+
+	if m.skipSchemaHash {
+		return nil
+	}
+
+	after, err := computeSchemaHash(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := dbsqlc.New().ReplaceSchemaHash(ctx, dbsqlc.WithTable(conn, m.table), dbsqlc.ReplaceSchemaHashParams{
+		OldHash: before,
+		NewHash: after,
+	}); err != nil {
+		return fmt.Errorf("failed to record schema hash of upgraded migrations table %s: %w", m.table, err)
+	}
+
+	return nil
+}
+
+// readHistory runs read against the history table and reports whether the
+// table exists; read is not run when it does not.
+//
+// A table created by an earlier version of conduit is read as if upgraded:
+// the upgrade runs in a transaction, or a savepoint of the transaction conn
+// is in, that is rolled back once read returns, so that reading never
+// changes the table. The table is upgraded for good by the operations that
+// take the advisory lock, see [Migrator.upgradeHistory].
+func (m *Migrator) readHistory(ctx context.Context, conn *pgx.Conn, read func() error) (bool, error) {
+	ok, err := m.historyExists(ctx, conn)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	outdated, err := m.historyOutdated(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	if !outdated {
+		return true, read()
+	}
+
+	begin, rollback := "BEGIN", "ROLLBACK"
+	if conn.PgConn().TxStatus() != 'I' {
+		begin, rollback = "SAVEPOINT conduit_read_history", "ROLLBACK TO SAVEPOINT conduit_read_history"
+	}
+
+	if _, err := conn.Exec(ctx, begin); err != nil {
+		return false, fmt.Errorf("failed to open transaction: %w", err)
+	}
+
+	defer func() {
+		if _, err := conn.Exec(context.WithoutCancel(ctx), rollback); err != nil {
+			m.logger.WarnContext(ctx, "failed to roll back the migrations table upgrade", "error", err)
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, string(migrations.UpgradeFor(m.table))); err != nil {
+		return false, fmt.Errorf("failed to upgrade migrations table %s for reading: %w", m.table, err)
+	}
+
+	return true, read()
+}
@@ -0,0 +1,382 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/pg-schema-diff/pkg/schema"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/internal/testutil"
+)
+
+// newLegacyHistory creates legacy.conduit_migrations with the given column
+// and key definitions, as an earlier version of conduit did, with
+// create_users recorded as applied.
+func newLegacyHistory(t *testing.T, pool *pgxpool.Pool, definitions string) {
+	t.Helper()
+
+	testutil.Exec(t, pool, "CREATE SCHEMA legacy;")
+	testutil.Exec(t, pool, "CREATE TABLE legacy.conduit_migrations ("+definitions+");")
+	testutil.Exec(t, pool, `INSERT INTO legacy.conduit_migrations (version, name, hash)
+		VALUES ('20230601120000', 'create_users', '');`)
+}
+
+// recordLegacySchemaHash creates the table of create_users and records the
+// hash of the live schema with it, as the migrator that applied it did.
+func recordLegacySchemaHash(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+
+	testutil.Exec(t, pool, "CREATE TABLE users (id INT);")
+
+	db := stdlib.OpenDB(*pool.Config().ConnConfig)
+	defer db.Close()
+
+	hash, err := schema.GetSchemaHash(t.Context(), db)
+	require.NoError(t, err)
+
+	_, err = pool.Exec(t.Context(), "UPDATE legacy.conduit_migrations SET hash = $1", hash)
+	require.NoError(t, err)
+}
+
+// legacyColumns returns the columns of legacy.conduit_migrations.
+func legacyColumns(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+
+	rows, err := pool.Query(t.Context(), `SELECT column_name::TEXT FROM information_schema.columns
+		WHERE table_schema = 'legacy' AND table_name = 'conduit_migrations'
+		ORDER BY ordinal_position`)
+	require.NoError(t, err)
+
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+
+	return columns
+}
+
+func TestMigrator_UpgradeHistory(t *testing.T) {
+	t.Parallel()
+
+	newMigrator := func(t *testing.T, opts ...conduit.Option) *conduit.Migrator {
+		t.Helper()
+
+		return conduit.NewMigrator(append([]conduit.Option{
+			conduit.WithRegistry(testregistry.NewRegistry(t, map[string]string{
+				"20230601120000_create_users.up.sql": "CREATE TABLE users (id INT);",
+				"20230602120000_create_posts.up.sql": "CREATE TABLE posts (id INT);",
+			})),
+			conduit.WithHistorySchema("legacy"),
+		}, opts...)...)
+	}
+
+	// predatesNamespaces are the definitions of a history table created
+	// before migrations had a namespace.
+	const predatesNamespaces = `
+		id BIGSERIAL NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		version VARCHAR(255) NOT NULL,
+		name VARCHAR(4095) NOT NULL,
+		hash VARCHAR(64) NOT NULL,
+		checksum VARCHAR(64) NOT NULL DEFAULT '',
+		dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+		last_statement INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (id),
+		UNIQUE (version, name)`
+
+	t.Run("should add namespace and replace key, when table predates namespaces", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, predatesNamespaces)
+		m := newMigrator(t, conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "create_posts", results[0].Name)
+		assert.Contains(t, legacyColumns(t, pool), "namespace")
+
+		_, err = pool.Exec(t.Context(), `INSERT INTO legacy.conduit_migrations (namespace, version, name, hash)
+			VALUES ('shield', '20230601120000', 'create_users', '');`)
+		require.NoError(t, err, "expected the key to allow the same migration in another namespace")
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 2)
+		assert.Len(t, report.Missing(), 1)
+	})
+
+	t.Run("should leave table unchanged, when status is read", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, predatesNamespaces)
+		columns := legacyColumns(t, pool)
+		m := newMigrator(t)
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, report.Applied(), 1)
+		assert.Equal(t, "20230601120000_create_users", report.Applied()[0].Key())
+		assert.Equal(t, columns, legacyColumns(t, pool))
+	})
+
+	t.Run("should pass drift check, when schema matched the recorded hash before upgrade", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, predatesNamespaces)
+		recordLegacySchemaHash(t, pool)
+		m := newMigrator(t)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "create_posts", results[0].Name)
+		assert.Contains(t, legacyColumns(t, pool), "namespace")
+	})
+
+	t.Run("should fail drift check, when schema drifted before upgrade", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, predatesNamespaces)
+		recordLegacySchemaHash(t, pool)
+		testutil.Exec(t, pool, "CREATE TABLE manual (id INT);")
+		m := newMigrator(t)
+
+		// Act
+		_, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.ErrorIs(t, err, conduit.ErrSchemaDrift)
+	})
+
+	t.Run("should add checksum, when table predates checksums", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, `
+			id BIGSERIAL NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			namespace VARCHAR(255) NOT NULL DEFAULT '',
+			version VARCHAR(255) NOT NULL,
+			name VARCHAR(4095) NOT NULL,
+			hash VARCHAR(64) NOT NULL,
+			dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+			last_statement INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (id),
+			UNIQUE (namespace, version, name)`)
+		m := newMigrator(t, conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "create_posts", results[0].Name)
+
+		rows, err := pool.Query(t.Context(), "SELECT checksum FROM legacy.conduit_migrations ORDER BY version")
+		require.NoError(t, err)
+		checksums, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+		require.Len(t, checksums, 2)
+		assert.Empty(t, checksums[0])
+		assert.NotEmpty(t, checksums[1])
+	})
+
+	t.Run("should pass drift check, when table predating checksums is upgraded", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, `
+			id BIGSERIAL NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			namespace VARCHAR(255) NOT NULL DEFAULT '',
+			version VARCHAR(255) NOT NULL,
+			name VARCHAR(4095) NOT NULL,
+			hash VARCHAR(64) NOT NULL,
+			dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+			last_statement INT NOT NULL DEFAULT 0,
+			PRIMARY KEY (id),
+			UNIQUE (namespace, version, name)`)
+		recordLegacySchemaHash(t, pool)
+		m := newMigrator(t)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Contains(t, legacyColumns(t, pool), "checksum")
+	})
+
+	t.Run("should report no dirty migration, when table predates dirty tracking", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, `
+			id BIGSERIAL NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			namespace VARCHAR(255) NOT NULL DEFAULT '',
+			version VARCHAR(255) NOT NULL,
+			name VARCHAR(4095) NOT NULL,
+			hash VARCHAR(64) NOT NULL,
+			checksum VARCHAR(64) NOT NULL DEFAULT '',
+			PRIMARY KEY (id),
+			UNIQUE (namespace, version, name)`)
+		m := newMigrator(t, conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		report, err := m.Status(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 1)
+		assert.Empty(t, report.Dirty())
+		assert.NotContains(t, legacyColumns(t, pool), "last_statement")
+	})
+
+	t.Run("should pass drift check, when table predating dirty tracking is upgraded", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, `
+			id BIGSERIAL NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			namespace VARCHAR(255) NOT NULL DEFAULT '',
+			version VARCHAR(255) NOT NULL,
+			name VARCHAR(4095) NOT NULL,
+			hash VARCHAR(64) NOT NULL,
+			checksum VARCHAR(64) NOT NULL DEFAULT '',
+			PRIMARY KEY (id),
+			UNIQUE (namespace, version, name)`)
+		recordLegacySchemaHash(t, pool)
+		m := newMigrator(t)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Contains(t, legacyColumns(t, pool), "last_statement")
+	})
+
+	t.Run("should migrate, when table was created by the first version of conduit", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, `
+			id BIGSERIAL NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			version VARCHAR(255) NOT NULL,
+			name VARCHAR(4095) NOT NULL,
+			hash VARCHAR(64) NOT NULL,
+			PRIMARY KEY (id),
+			UNIQUE (version, name)`)
+		m := newMigrator(t, conduit.WithSkipSchemaDriftCheck())
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		results := testutil.CollectSeq2(t, seq)
+
+		// Assert
+		require.Len(t, results, 1)
+		assert.Equal(t, "create_posts", results[0].Name)
+
+		report, err := m.Status(t.Context(), conn)
+		require.NoError(t, err)
+		assert.Len(t, report.Applied(), 2)
+		assert.Empty(t, report.Pending())
+	})
+
+	// predatesLog are the definitions of a history table created before
+	// the migration log existed.
+	const predatesLog = `
+		id BIGSERIAL NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		namespace VARCHAR(255) NOT NULL DEFAULT '',
+		version VARCHAR(255) NOT NULL,
+		name VARCHAR(4095) NOT NULL,
+		hash VARCHAR(64) NOT NULL,
+		checksum VARCHAR(64) NOT NULL DEFAULT '',
+		dirty_direction VARCHAR(4) NOT NULL DEFAULT '',
+		last_statement INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (id),
+		UNIQUE (namespace, version, name)`
+
+	t.Run("should log migrations, when table predates the log", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		newLegacyHistory(t, pool, predatesLog)
+		recordLegacySchemaHash(t, pool)
+		m := newMigrator(t)
+
+		_, err := m.History(t.Context(), conn, 0)
+		require.ErrorIs(t, err, conduit.ErrNoMigrationLog)
+
+		// Act
+		seq, err := m.Migrate(t.Context(), conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+		testutil.CollectSeq2(t, seq)
+
+		// Assert
+		entries, err := m.History(t.Context(), conn, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "20230602120000_create_posts", entries[0].Key())
+	})
+
+	t.Run("should report no drift, when table predates the log", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		testutil.Exec(t, pool, "DROP TABLE conduit_migrations, conduit_migrations_log;")
+		newLegacyHistory(t, pool, predatesLog)
+		testutil.Exec(t, pool, "CREATE TABLE users (id INT);")
+		m := newMigrator(t)
+
+		// Act
+		report, err := m.Drift(t.Context(), conn)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift(), report.Statements)
+		assert.False(t, tenantTableExists(t, pool, "legacy", "conduit_migrations_log"))
+	})
+}
@@ -0,0 +1,103 @@
+package conduit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.inout.gg/conduit/internal/tracing"
+)
+
+var ErrSchemaVerification = errors.New("schema verification failed")
+
+// SchemaVerificationError is returned when, with [WithSchemaVerification],
+// the live schema after a migration does not match the schema the applied
+// migrations describe, e.g. because the migration behaved differently on
+// the data or extensions of the database.
+//
+// The migration has already been applied, or rolled back, and recorded when
+// the error is returned; its [MigrationResult] is not yielded.
+type SchemaVerificationError struct {
+	// Report lists the objects that differ and the DDL that reconciles them.
+	Report *DriftReport
+
+	// Key is the key of the migration after which the schema was verified.
+	Key string
+
+	// Direction is the direction the migration ran in.
+	Direction Direction
+}
+
+func (e *SchemaVerificationError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s after %s migration %s:", ErrSchemaVerification, e.Direction, e.Key)
+
+	for _, o := range e.Report.Objects {
+		fmt.Fprintf(&b, "\n  - %s", formatDriftedObject(o))
+	}
+
+	if len(e.Report.Skipped) > 0 {
+		fmt.Fprintf(
+			&b,
+			"\nnot replayed, objects they create show as unexpected: %s",
+			strings.Join(e.Report.Skipped, ", "),
+		)
+	}
+
+	b.WriteString("\nstatements that reconcile the live schema with the expected one:")
+
+	for _, stmt := range e.Report.Statements {
+		fmt.Fprintf(&b, "\n  %s;", stmt.DDL)
+	}
+
+	return b.String()
+}
+
+func (e *SchemaVerificationError) Unwrap() error { return ErrSchemaVerification }
+
+// verify compares the live schema with the schema the applied
+// migrations describe, once migration ran in direction dir.
+//
+// Inside a caller-supplied transaction, the schema is not verified: the
+// expected schema is built from the history the transaction sees, while the
+// live schema is read on a separate connection that does not see it.
+func (m *Migrator) verify(
+	ctx context.Context,
+	conn *pgx.Conn,
+	migration *Migration,
+	dir Direction,
+) (err error) {
+	if _, ok := callerTx(ctx); ok {
+		m.logger.WarnContext(
+			ctx,
+			"skipping schema verification inside a caller-supplied transaction",
+			slog.String("migration", migration.Key()),
+		)
+
+		return nil
+	}
+
+	ctx, span := tracing.FromContext(ctx).Start(
+		ctx,
+		"conduit.schema_verification",
+		trace.WithAttributes(migrationAttributes(migration, dir)...),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	report, err := m.driftReport(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("failed to verify schema after migration %s: %w", migration.Key(), err)
+	}
+
+	if report.HasDrift() {
+		return &SchemaVerificationError{Report: report, Key: migration.Key(), Direction: dir}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package conduit_test
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.inout.gg/conduit"
+	"go.inout.gg/conduit/internal/testregistry"
+	"go.inout.gg/conduit/pkg/pgdiff"
+)
+
+func TestMigrator_Migrate_VerifySchema(t *testing.T) {
+	t.Parallel()
+
+	// The migrations depend on the data of table a, which the temporary
+	// database they are replayed in does not have.
+	files := map[string]string{
+		"20230601120000_create_a.up.sql": "CREATE TABLE a (id INT);",
+		"20230602120000_index_a.up.sql": `DO $$ BEGIN
+	IF EXISTS (SELECT 1 FROM a) THEN CREATE INDEX a_id_idx ON a (id); END IF;
+END $$;`,
+		"20230603120000_create_b.up.sql": "CREATE TABLE b (id INT);",
+		"20230603120000_create_b.down.sql": `DO $$ BEGIN
+	IF NOT EXISTS (SELECT 1 FROM a) THEN DROP TABLE b; END IF;
+END $$;`,
+	}
+
+	migrate := func(
+		t *testing.T,
+		m *conduit.Migrator,
+		dir conduit.Direction,
+		conn *pgx.Conn,
+		opts *conduit.MigrateOptions,
+	) ([]*conduit.MigrationResult, error) {
+		t.Helper()
+
+		seq, err := m.Migrate(t.Context(), dir, conn, opts)
+		require.NoError(t, err)
+
+		var (
+			results []*conduit.MigrationResult
+			runErr  error
+		)
+
+		for result, err := range seq {
+			if err != nil {
+				runErr = err
+				break
+			}
+
+			results = append(results, result)
+		}
+
+		return results, runErr
+	}
+
+	t.Run("should verify every migration, when the schema matches", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		_, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithSchemaVerification(),
+		)
+
+		// Act
+		results, err := migrate(t, m, conduit.DirectionUp, conn, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, results, 3)
+	})
+
+	t.Run("should fail with the diff, when an up migration behaves differently", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithSchemaVerification(),
+		)
+
+		_, err := migrate(t, m, conduit.DirectionUp, conn, &conduit.MigrateOptions{Steps: 1})
+		require.NoError(t, err)
+
+		_, err = pool.Exec(t.Context(), "INSERT INTO a VALUES (1)")
+		require.NoError(t, err)
+
+		// Act
+		results, err := migrate(t, m, conduit.DirectionUp, conn, nil)
+
+		// Assert
+		var verifyErr *conduit.SchemaVerificationError
+		require.ErrorAs(t, err, &verifyErr)
+		require.ErrorIs(t, err, conduit.ErrSchemaVerification)
+		assert.Equal(t, "20230602120000_index_a", verifyErr.Key)
+		assert.Equal(t, conduit.DirectionUp, verifyErr.Direction)
+		assert.Contains(t, verifyErr.Report.Objects, pgdiff.DriftedObject{
+			Kind:   pgdiff.ObjectKindIndex,
+			Name:   "public.a_id_idx",
+			Change: pgdiff.ObjectChangeUnexpected,
+		})
+		assert.NotEmpty(t, verifyErr.Report.Statements)
+		assert.Empty(t, results, "a migration that fails verification yields only the error")
+		assert.Len(t, appliedMigrations(t, pool), 2, "the migration is applied before it is verified")
+	})
+
+	t.Run("should fail with the diff, when a down migration behaves differently", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		pool, conn := newConn(t)
+		m := conduit.NewMigrator(
+			conduit.WithRegistry(testregistry.NewRegistry(t, files)),
+			conduit.WithSchemaVerification(),
+		)
+
+		_, err := migrate(t, m, conduit.DirectionUp, conn, nil)
+		require.NoError(t, err)
+
+		_, err = pool.Exec(t.Context(), "INSERT INTO a VALUES (1)")
+		require.NoError(t, err)
+
+		// Act
+		results, err := migrate(t, m, conduit.DirectionDown, conn, nil)
+
+		// Assert
+		var verifyErr *conduit.SchemaVerificationError
+		require.ErrorAs(t, err, &verifyErr)
+		assert.Empty(t, results, "a migration that fails verification yields only the error")
+		assert.Equal(t, "20230603120000_create_b", verifyErr.Key)
+		assert.Equal(t, conduit.DirectionDown, verifyErr.Direction)
+		assert.Contains(t, verifyErr.Report.Objects, pgdiff.DriftedObject{
+			Kind:   pgdiff.ObjectKindTable,
+			Name:   "public.b",
+			Change: pgdiff.ObjectChangeUnexpected,
+		})
+	})
+}